@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"github.com/ThinkiumGroup/web3.go/common/hexutil"
+	"github.com/ThinkiumGroup/web3.go/web3/thk"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/abi"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"sort"
+	"strings"
+)
+
+// loadContract reads the json or human-readable abi in the file, the contract is used for the encoding
+// only, so it's bound to no provider
+func (c *cli) loadContract(path string) (*thk.Contract, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%w: no -abi", errUsage)
+	}
+	b, err := readFile(path, c.stdin)
+	if err != nil {
+		return nil, err
+	}
+	contract, err := thk.NewThk(nil).NewContract(string(b))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return contract, nil
+}
+
+// method returns the method of the abi, or the constructor by "constructor"
+func method(contract *thk.Contract, name string) (abi.Method, error) {
+	if name == "constructor" {
+		return contract.ABI().Constructor, nil
+	}
+	m, ok := contract.ABI().Methods[name]
+	if !ok {
+		names := make([]string, 0, len(contract.ABI().Methods))
+		for name := range contract.ABI().Methods {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return abi.Method{}, fmt.Errorf("no method %s in the abi, methods: %s", name, strings.Join(names, ", "))
+	}
+	return m, nil
+}
+
+// encode packs the arguments in strings by the method
+func encode(contract *thk.Contract, name string, args []string) (string, error) {
+	m, err := method(contract, name)
+	if err != nil {
+		return "", err
+	}
+	values, err := m.Inputs.ParseArgs(args)
+	if err != nil {
+		return "", err
+	}
+	if name == "constructor" {
+		name = ""
+	}
+	return contract.GetInput(name, values...)
+}
+
+// decodedInput converts the decoded arguments for printing
+func decodedInput(decoded *abi.DecodedInput) map[string]interface{} {
+	args := make([]map[string]interface{}, len(decoded.Args))
+	for i, arg := range decoded.Args {
+		args[i] = map[string]interface{}{"name": arg.Name, "type": arg.Type, "value": displayValue(arg.Value)}
+	}
+	return map[string]interface{}{"name": decoded.Name, "sig": decoded.Sig, "args": args}
+}
+
+// decodeOutput unpacks the returned values of the method
+func decodeOutput(m abi.Method, out string) ([]map[string]interface{}, error) {
+	data, err := hexutil.Decode(out)
+	if err != nil {
+		return nil, err
+	}
+	values, err := m.Outputs.UnpackValues(data)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]map[string]interface{}, len(values))
+	for i, value := range values {
+		ret[i] = map[string]interface{}{"name": m.Outputs[i].Name, "type": m.Outputs[i].Type.String(), "value": displayValue(value)}
+	}
+	return ret, nil
+}
+
+func (c *cli) contract(args []string) error {
+	name, args, err := subcommand(args, "call", "send", "deploy")
+	if err != nil {
+		return err
+	}
+	fs := c.flags("contract " + name)
+	abiFile := fs.String("abi", "", "abi file of the contract")
+	var to, from, bin, value *string
+	var f *txFlags
+	switch name {
+	case "call":
+		to = fs.String("to", "", "address of the contract")
+		from = fs.String("from", "", "address of the caller, the contract by default")
+	case "send":
+		to = fs.String("to", "", "address of the contract")
+	case "deploy":
+		bin = fs.String("bin", "", "file of the bytecode in hex")
+	}
+	if name != "call" {
+		value = fs.String("value", "", "amount in Wei or with a unit, such as 1.5 TKM")
+		f = addTxFlags(fs)
+	}
+	min := 1
+	if name == "deploy" {
+		min = 0
+	}
+	if err := parse(fs, args, min, -1); err != nil {
+		return err
+	}
+	contract, err := c.loadContract(*abiFile)
+	if err != nil {
+		return err
+	}
+	if to != nil && !isAddress(*to) {
+		return fmt.Errorf("%w: invalid -to %q", errUsage, *to)
+	}
+
+	switch name {
+	case "call":
+		m, err := method(contract, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		input, err := encode(contract, fs.Arg(0), fs.Args()[1:])
+		if err != nil {
+			return err
+		}
+		client, chainId, err := c.query()
+		if err != nil {
+			return err
+		}
+		if *from == "" {
+			*from = *to
+		}
+		res, err := client.Thk.CallTransaction(&util.Transaction{ChainId: chainId, FromChainId: chainId, ToChainId: chainId,
+			From: *from, To: *to, Value: "0", Nonce: "0", Input: input})
+		if err != nil {
+			return err
+		}
+		if err := contract.UnpackError(res); err != nil {
+			return err
+		}
+		outputs, err := decodeOutput(m, res.Out)
+		if err != nil {
+			return err
+		}
+		return c.print(map[string]interface{}{"gasUsed": res.GasUsed, "out": res.Out, "outputs": outputs})
+	case "send":
+		input, err := encode(contract, fs.Arg(0), fs.Args()[1:])
+		if err != nil {
+			return err
+		}
+		client, key, tx, err := c.newTx(*to, *value, input)
+		if err != nil {
+			return err
+		}
+		if err := f.sign(client, key, tx); err != nil {
+			return err
+		}
+		return c.send(client, tx)
+	}
+
+	if *bin == "" {
+		return fmt.Errorf("%w: no -bin", errUsage)
+	}
+	code, err := readFile(*bin, nil)
+	if err != nil {
+		return err
+	}
+	bytecode := strings.TrimSpace(string(code))
+	if !strings.HasPrefix(bytecode, "0x") {
+		bytecode = "0x" + bytecode
+	}
+	if _, err := hexutil.Decode(bytecode); err != nil {
+		return fmt.Errorf("%s: %v", *bin, err)
+	}
+	input, err := encode(contract, "constructor", fs.Args())
+	if err != nil {
+		return err
+	}
+	client, key, tx, err := c.newTx("", *value, bytecode+strings.TrimPrefix(input, "0x"))
+	if err != nil {
+		return err
+	}
+	if err := f.sign(client, key, tx); err != nil {
+		return err
+	}
+	hash, err := client.Thk.SendTx(tx)
+	if err != nil {
+		return err
+	}
+	address, err := thk.ContractAddress(tx.From, tx.Nonce)
+	if err != nil {
+		return err
+	}
+	return c.print(map[string]string{"chainId": tx.ChainId, "from": tx.From, "nonce": tx.Nonce, "hash": hash, "address": address})
+}
+
+func (c *cli) abi(args []string) error {
+	name, args, err := subcommand(args, "encode", "decode")
+	if err != nil {
+		return err
+	}
+	fs := c.flags("abi " + name)
+	abiFile := fs.String("abi", "", "abi file of the contract")
+	var output *string
+	max := -1
+	if name == "decode" {
+		output = fs.String("output", "", "decode the returned values of the method instead of the input")
+		max = 1
+	}
+	if err := parse(fs, args, 1, max); err != nil {
+		return err
+	}
+	contract, err := c.loadContract(*abiFile)
+	if err != nil {
+		return err
+	}
+	if name == "encode" {
+		input, err := encode(contract, fs.Arg(0), fs.Args()[1:])
+		if err != nil {
+			return err
+		}
+		return c.print(map[string]string{"input": input})
+	}
+	if *output != "" {
+		m, err := method(contract, *output)
+		if err != nil {
+			return err
+		}
+		outputs, err := decodeOutput(m, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		return c.print(outputs)
+	}
+	decoded, err := contract.DecodeInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return c.print(decodedInput(decoded))
+}
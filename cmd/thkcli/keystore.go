@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"github.com/ThinkiumGroup/web3.go/common"
+	"github.com/ThinkiumGroup/web3.go/common/hexutil"
+	"github.com/ThinkiumGroup/web3.go/web3/keystore"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// keystore creates, imports and checks the keystores, the password is read by -password-file or
+// $THK_KEYSTORE_PASSWORD, and the private keys are never printed
+func (c *cli) keystore(args []string) error {
+	name, args, err := subcommand(args, "new", "import", "inspect")
+	if err != nil {
+		return err
+	}
+	fs := c.flags("keystore " + name)
+	var light *bool
+	var out *string
+	if name != "inspect" {
+		light = fs.Bool("light", false, "use the light scrypt parameters, which are faster and weaker")
+		out = fs.String("out", "", "keystore file to write, <address>.json by default")
+	}
+	n := 0
+	if name != "new" {
+		n = 1
+	}
+	if err := parse(fs, args, n, n); err != nil {
+		return err
+	}
+	password, err := c.password()
+	if err != nil {
+		return err
+	}
+
+	if name == "inspect" {
+		key, err := keystore.ReadKeyFile(fs.Arg(0), password)
+		if err != nil {
+			return err
+		}
+		return c.print(map[string]string{"address": key.Address, "file": fs.Arg(0)})
+	}
+
+	if *out != "" {
+		if _, err := os.Stat(*out); err == nil {
+			return fmt.Errorf("%s exists", *out)
+		}
+	}
+	var privateKey string
+	if name == "new" {
+		priv, err := common.Cipher.GenerateKey()
+		if err != nil {
+			return err
+		}
+		privateKey = hexutil.Encode(common.Cipher.PrivToBytes(priv))
+	} else {
+		b, err := readFile(fs.Arg(0), c.stdin)
+		if err != nil {
+			return err
+		}
+		privateKey = strings.TrimSpace(string(b))
+		if !strings.HasPrefix(privateKey, "0x") {
+			privateKey = "0x" + privateKey
+		}
+	}
+	scryptN, scryptP := keystore.StandardScryptN, keystore.StandardScryptP
+	if *light {
+		scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+	}
+	keyjson, err := keystore.EncryptKey(privateKey, password, scryptN, scryptP)
+	if err != nil {
+		return err
+	}
+	key, err := keystore.DecryptKey(keyjson, password)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		*out = key.Address + ".json"
+		if _, err := os.Stat(*out); err == nil {
+			return fmt.Errorf("%s exists", *out)
+		}
+	}
+	if err := ioutil.WriteFile(*out, keyjson, 0600); err != nil {
+		return err
+	}
+	return c.print(map[string]string{"address": key.Address, "file": *out})
+}
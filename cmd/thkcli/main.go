@@ -0,0 +1,255 @@
+// Command thkcli queries the Thinkium nodes and sends transactions by the SDK.
+//
+//	thkcli [-config file] [-endpoint address] [-chain id] [-o json|table] <command> [flags] [args]
+//
+// The endpoints, network and signer are loaded from the config file of web3.LoadConfig, which is
+// also given by $THK_CONFIG, or from the THK_* environment variables. Run "thkcli help" for the
+// commands.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ThinkiumGroup/web3.go/web3"
+	"github.com/ThinkiumGroup/web3.go/web3/keystore"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+type command struct {
+	usage string
+	run   func(c *cli, args []string) error
+}
+
+var commands = map[string]command{
+	"account":   {"account <address>", (*cli).account},
+	"balance":   {"balance [-unit TKM] <address>", (*cli).balance},
+	"nonce":     {"nonce <address>", (*cli).nonce},
+	"stats":     {"stats", (*cli).stats},
+	"committee": {"committee <epoch>", (*cli).committee},
+	"block":     {"block get [-header] <height>", (*cli).block},
+	"tx":        {"tx send|sign [tx flags] | tx decode [-abi file] <file|-> | tx get <hash>", (*cli).tx},
+	"cheque":    {"cheque write -to address -to-chain id -value amount [-expire blocks] | cheque cash|cancel <cheque file>", (*cli).cheque},
+	"contract":  {"contract call|send -abi file -to address [tx flags] <method> [args] | contract deploy -abi file -bin file [tx flags] [args]", (*cli).contract},
+	"abi":       {"abi encode -abi file <method> [args] | abi decode -abi file [-output method] <hex>", (*cli).abi},
+	"keystore":  {"keystore new [-light] [-out file] | keystore import [-light] [-out file] <key file> | keystore inspect <file>", (*cli).keystore},
+}
+
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	configPath   string
+	endpoint     string
+	chainId      string
+	output       string
+	keystorePath string
+	passwordFile string
+
+	config *web3.Config
+	client *web3.Web3
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line, and returns the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := &cli{stdin: stdin, stdout: stdout, stderr: stderr}
+	fs := flag.NewFlagSet("thkcli", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&c.configPath, "config", os.Getenv("THK_CONFIG"), "config file in YAML or JSON")
+	fs.StringVar(&c.endpoint, "endpoint", "", "address of the node, overrides the config")
+	fs.StringVar(&c.chainId, "chain", "", "chain id, the chainId of the config by default")
+	fs.StringVar(&c.output, "o", "json", "output format: json or table")
+	fs.StringVar(&c.keystorePath, "keystore", "", "keystore file of the signer, overrides the config")
+	fs.StringVar(&c.passwordFile, "password-file", "", "password file of the keystore, $"+web3.DefaultPasswordEnv+" by default")
+	fs.Usage = func() { c.usage(fs) }
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if c.output != "json" && c.output != "table" {
+		fmt.Fprintf(stderr, "unknown output format %q\n", c.output)
+		return 2
+	}
+	if fs.NArg() == 0 || fs.Arg(0) == "help" {
+		c.usage(fs)
+		return 2
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n", fs.Arg(0))
+		c.usage(fs)
+		return 2
+	}
+	if err := cmd.run(c, fs.Args()[1:]); err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		if errors.Is(err, errUsage) {
+			fmt.Fprintln(stderr, "usage: thkcli", cmd.usage)
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+func (c *cli) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(c.stderr, "usage: thkcli [flags] <command> [flags] [args]\n\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintln(c.stderr, "  "+commands[name].usage)
+	}
+	fmt.Fprintln(c.stderr, "\nflags:")
+	fs.PrintDefaults()
+}
+
+var errUsage = errors.New("invalid arguments")
+
+// flags returns the flag set of the command, its errors are printed to stderr
+func (c *cli) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	return fs
+}
+
+// parse parses the flags of the command, and checks the count of the arguments
+func parse(fs *flag.FlagSet, args []string, min, max int) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() < min || (max >= 0 && fs.NArg() > max) {
+		return errUsage
+	}
+	return nil
+}
+
+// subcommand splits the subcommand from the arguments
+func subcommand(args []string, names ...string) (string, []string, error) {
+	if len(args) > 0 {
+		for _, name := range names {
+			if args[0] == name {
+				return name, args[1:], nil
+			}
+		}
+	}
+	return "", nil, fmt.Errorf("%w: expect %s", errUsage, strings.Join(names, ", "))
+}
+
+func (c *cli) loadConfig() (*web3.Config, error) {
+	if c.config != nil {
+		return c.config, nil
+	}
+	var config *web3.Config
+	var err error
+	if c.configPath != "" {
+		config, err = web3.LoadConfig(c.configPath)
+	} else {
+		config, err = web3.ConfigFromEnv()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.endpoint != "" {
+		config.Endpoint.Address = c.endpoint
+	}
+	c.config = config
+	return config, nil
+}
+
+// web3 builds the client by the config, the keystores are read only when signing
+func (c *cli) web3() (*web3.Web3, error) {
+	if c.client != nil {
+		return c.client, nil
+	}
+	config, err := c.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	unsigned := *config
+	unsigned.Signer, unsigned.ExtraSigners = nil, nil
+	client, err := web3.NewWeb3FromConfig(&unsigned)
+	if err != nil {
+		return nil, err
+	}
+	c.client = client
+	return client, nil
+}
+
+// chain returns the chain id of the -chain flag or the config
+func (c *cli) chain() (string, error) {
+	if c.chainId != "" {
+		return c.chainId, nil
+	}
+	config, err := c.loadConfig()
+	if err != nil {
+		return "", err
+	}
+	if config.ChainId == "" {
+		return "", errors.New("no chain id, set -chain or chainId of the config")
+	}
+	return config.ChainId.String(), nil
+}
+
+// query returns the client and the chain id for the queries
+func (c *cli) query() (*web3.Web3, string, error) {
+	client, err := c.web3()
+	if err != nil {
+		return nil, "", err
+	}
+	chainId, err := c.chain()
+	if err != nil {
+		return nil, "", err
+	}
+	return client, chainId, nil
+}
+
+// network returns the network of the config, which needs no endpoint
+func (c *cli) network() (*util.Network, error) {
+	config, err := c.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return config.Network.Network(), nil
+}
+
+// signer reads the key of the -keystore flag or the signer of the config
+func (c *cli) signer() (*keystore.Key, error) {
+	if c.keystorePath != "" {
+		signer := &web3.SignerConfig{Keystore: c.keystorePath, PasswordFile: c.passwordFile}
+		return signer.Key()
+	}
+	config, err := c.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if config.Signer == nil {
+		return nil, errors.New("no signer, set -keystore or signer of the config")
+	}
+	return config.Signer.Key()
+}
+
+// password reads the password of the -password-file flag or $THK_KEYSTORE_PASSWORD
+func (c *cli) password() (string, error) {
+	if c.passwordFile != "" {
+		b, err := readFile(c.passwordFile, nil)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	}
+	password, ok := os.LookupEnv(web3.DefaultPasswordEnv)
+	if !ok {
+		return "", fmt.Errorf("no password, set -password-file or $%s", web3.DefaultPasswordEnv)
+	}
+	return password, nil
+}
@@ -0,0 +1,494 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/ThinkiumGroup/web3.go/web3/keystore"
+	"github.com/ThinkiumGroup/web3.go/web3/thk"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	testKey      = "0x8e5b44b6cee8fa05092b4b5a8843aa6b0ec37915a940c9b5938e88a7e6fdd83a"
+	testContract = "0x0e50cea0402d2a396b0db1c5d08155bd219cc52e"
+	testABI      = "function transfer(address to, uint256 amount) returns (bool)\nfunction balanceOf(address owner) view returns (uint256)"
+
+	// inputs of the proofs to cash and cancel a cheque from chain 1 to chain 2
+	testCashInput   = "0x9500000001700fe44d941225d58e695c449f79412cc7fdbcf8000000000000000800000002700fe44d941225d58e695c449f79412cc7fdbcf80000000000cd4ab00a0808bb44f943d050000000a40115c15bc00a6ecc6c41df074d3b206b4c92bf0135eaa1ad2e1bc5cb8ea19b5c0d90b5faf297941093a1b1de98b98e55dcd716559a4637f8d1093d3d2094acfb23a22d4a6beed4b8fc01c200008080940b934080c2ad4b80810004873886e8ed6d1fb4cfb76ee3a51743017fb843d4d75aebd1c7ff74af54a11efe6fe278546f23095d9045730bf9754685dc95a408c4c7187b75dd5c56ae2e4e40287dd0ee81fb16613e9c19f51b9ca42dd34bc08339a27817d402f7959b13f5235308633a3ff8f0cb4de954288da3be5873100efe659c25ef05cd6bc0653e7257000107940e934080c2ffff80810004511655f6352ed0decc7276b684df10bb46feff9a908acf0df2bd6016a17ec973a03f7a59bd2949c4f5d4a38522e7ca2a3c2f4734cbf3303a6cf40f5d037a25016ec88920b0aed933b26975be441f071e7bd8f93e78d5ca1430177b67d7d5fb0c6d0e347c5679245f580a4b2da483c3019e575111b6ce65978fa59d220db2548000010e9404934080c2ffff80810004844fba978763858c37126d7c256598ea19a781b6ed81af5bd152c40d406f60fff11def743b04694881d7493ad4c3cb448f00ed0ab90e11d6a587fd99f0dc0e4986adab2d712eefb87c4c5c462a36efa0a8ea8e33103f7a0d21e7998fc582c00dce7e3cc6f7f75806eff5c6a5cc687e9c04f90079ae5b34b65a4b5cbf6f0070c40001049424930080c20000c089e7efb17aeab2d0c342d07f124096cb88aa39b6e634d560e4ae49d5cab5ee53810005423ba7e430ec3184e82c8df70aa2c5df8af08a01dae25e1331089cb7c3a29ec508119a9021bd4e60a1630ed667f901dccc3464f02ff1f203200b87d0152b1c915c3e0f832b46bcce0f17b37c08c1a268808ff789fae75d6709a702c7e1727a66d9c741a52af4683582993cf7839c8597457a0befe67682bee502d1f21e49db22db14fadeae9557e9abb544add89d2a2d22c35591f04b178f18e253f827cc03f80001109411930080c20000c012440ed40e7975972d7b55218afd52f6f7208d7cd9cd87f498717c3d77c3e5ec810002b95a5048621c6adff0e6e0501ed5f83ee6a8c195c21914dc3cff8e9ceb79b27d8f1cf6230f0498dc26f33ddb993633646822c8da316cf4574bdcb2f86872990c00009426930080c20000c0fa4cd1b148975f4b52aeb6a82f63ed2e90f9ede7c138dab58b1a135f578a959e810005b2cbeb6508244f5c9a8a0ee618068ef410d904d42bd652b6cf25576c58b04e89e2c0da358e91eed86a0658f202bb8fb2050f9e82cf7e9ba51b02bf35a7f3195f731728437fd64a11e98169b11432e9c9ebf78ca70978206e19a72ad539d82180d98df14cbc3939a40effc8c9b294d8af3733caa3370b2adc34f74c6730fd54a0590f25f3e5de0665d4004ee5514a7ab97587284f8d7ec07755a722990fba7bc9000114"
+	testCancelInput = "0x9600000001700fe44d941225d58e695c449f79412cc7fdbcf8000000000000000500000002700fe44d941225d58e695c449f79412cc7fdbcf80000000000cd35940a080900a88ac61544000001a3cd49bdc01ed94d694f64b56225597e0cafe84a5efa709668ec8a3e4263095ff77052a7929394a1fe934080c2808280810001a09b033a1b8e6c386cf40180fafd9b9b335d9bb2ff665fa839b8de677788daac00009402934080c2ffff808100042364b4ed58f756968196789b56db2c3b1bd17b8b6044e551509d192b41db94404e7362b575d64f6c427b95366f603c9ebccbdd7bc71794fbca0c397a86836109f5bb809bb7812adf54cda134eb20eeb52224114087820bf35460afb959f3d8fc17346d5b3b64c12a0dd1bf330b2b44f9e58b9fe8350b61b70bca9f4fc96f63b2000102940a934080c2ffff80810004389db1c4fcf2cb709480a8b7ad196550c3dd7d1cc80b81057c293157f784a23fd91f1c67631704b1e5048919c2de81dc7141da9ac69bf4a4a500d71f9042e9d3d8b99f2e75c80afef28f9384917c983cc08c9c53ecdff238a309acfd6220538291017b8ffabe46fe47d45fa88aba0e238ffa40ed61cc84f06a0ee5eb285dacb300010a919425930080c20000c0c5e5be3bc5e6df278b0a01e44cdded05cfe69ee1c592e662dc6bad7ac08e4bd3810005d07655ca81f8d75de2a376801feedcf348f5ff21a1c04c45eb3b146c11456b5413d5086b86bf654eb34ab7317fe0b70109e55f6376999cad571b927be0d507222f8e331e16e453e0da085eadb2705e556b4bb4693835b3f22c1c17f1dcfccc425998e44e70d863daea2d602751182a878eaa7c6e4449325e14d92f13287df5dfea4079ecb21ff0770dc9febc35bc47160ac167362f51a0ac27edcd156d4b3da8000111"
+)
+
+// node is a mock node answering the methods by the results or the errors, the params are recorded
+type node struct {
+	*httptest.Server
+	lock    sync.Mutex
+	results map[string]interface{}
+	params  map[string]map[string]interface{}
+}
+
+func newNode(t *testing.T, results map[string]interface{}) *node {
+	n := &node{results: results, params: make(map[string]map[string]interface{})}
+	n.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage        `json:"id"`
+			Method string                 `json:"method"`
+			Params map[string]interface{} `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		n.lock.Lock()
+		n.params[req.Method] = req.Params
+		result, ok := n.results[req.Method]
+		n.lock.Unlock()
+		if !ok {
+			t.Errorf("unexpected method %s", req.Method)
+		}
+		if err, ok := result.(error); ok {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID,
+				"error": map[string]interface{}{"code": -32000, "message": err.Error()}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	return n
+}
+
+func (n *node) param(method, name string) interface{} {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.params[method][name]
+}
+
+// thkcli runs the command line, and returns the exit code and the outputs
+func thkcli(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(""), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "thkcli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func writeFile(t *testing.T, path, content string) string {
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// signer writes the keystore of testKey, and returns the flags to sign by it on the node
+func signer(t *testing.T, dir string, n *node) []string {
+	keyjson, err := keystore.EncryptKey(testKey, "secret", 1<<4, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := writeFile(t, filepath.Join(dir, "key.json"), string(keyjson))
+	passwordFile := writeFile(t, filepath.Join(dir, "password"), "secret\n")
+	return []string{"-endpoint", n.URL, "-chain", "1", "-keystore", keyFile, "-password-file", passwordFile}
+}
+
+func TestStats(t *testing.T) {
+	n := newNode(t, map[string]interface{}{"GetStats": map[string]interface{}{"chainId": 1, "currentheight": 100}})
+	defer n.Close()
+
+	code, out, errOut := thkcli("-endpoint", n.URL, "-chain", "1", "stats")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	var stats map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats["currentheight"] != float64(100) {
+		t.Errorf("unexpected stats %s", out)
+	}
+	if n.param("GetStats", "chainId") != "1" {
+		t.Errorf("stats of chain %v", n.param("GetStats", "chainId"))
+	}
+
+	code, out, _ = thkcli("-endpoint", n.URL, "-chain", "1", "-o", "table", "stats")
+	if code != 0 || !strings.Contains(out, "currentheight      100\n") {
+		t.Errorf("unexpected table:\n%s", out)
+	}
+}
+
+func TestBalanceByConfig(t *testing.T) {
+	dir, clean := tempDir(t)
+	defer clean()
+	n := newNode(t, map[string]interface{}{"GetAccount": map[string]interface{}{"balance": 1500000000000000000}})
+	defer n.Close()
+	config := writeFile(t, filepath.Join(dir, "config.yaml"), "endpoint:\n  address: "+n.URL+"\nchainId: 2\n")
+
+	code, out, errOut := thkcli("-config", config, "-o", "table", "balance", "-unit", "finney", testContract)
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	for _, row := range []string{"chainId    2", "formatted  1500 Finney", "wei        1500000000000000000"} {
+		if !strings.Contains(out, row) {
+			t.Errorf("no %q in:\n%s", row, out)
+		}
+	}
+}
+
+func TestUsage(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"unknown"},
+		{"balance"},
+		{"-o", "xml", "stats"},
+		{"block", "put", "1"},
+		{"tx", "send", "-to", "0x01"},
+		{"committee", "latest"},
+	} {
+		if code, _, errOut := thkcli(args...); code != 2 {
+			t.Errorf("%v: exit %d: %s", args, code, errOut)
+		}
+	}
+}
+
+func TestTxSend(t *testing.T) {
+	dir, clean := tempDir(t)
+	defer clean()
+	n := newNode(t, map[string]interface{}{
+		"GetAccount": map[string]interface{}{"nonce": 7},
+		"SendTx":     map[string]interface{}{"TXhash": "0xabcd"},
+	})
+	defer n.Close()
+	signer := signer(t, dir, n)
+
+	code, out, errOut := thkcli(append(signer, "tx", "send", "-to", testContract, "-value", "1.5 TKM")...)
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	if !strings.Contains(out, `"hash": "0xabcd"`) {
+		t.Errorf("unexpected output %s", out)
+	}
+	if n.param("SendTx", "nonce") != "7" || n.param("SendTx", "value") != "1500000000000000000" ||
+		n.param("SendTx", "to") != testContract || n.param("SendTx", "sig") == "" {
+		t.Errorf("unexpected tx %v", n.params["SendTx"])
+	}
+
+	// sign with the given nonce and gas, decode and send it
+	code, out, errOut = thkcli(append(signer, "tx", "sign", "-to", testContract, "-nonce", "9", "-gas", "30000")...)
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	txFile := writeFile(t, filepath.Join(dir, "tx.json"), out)
+	code, out, errOut = thkcli("-endpoint", n.URL, "tx", "decode", txFile)
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	var decoded struct {
+		SigningHash string `json:"signingHash"`
+		Gas         struct {
+			Gas uint64 `json:"gas"`
+		} `json:"gas"`
+	}
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.SigningHash == "" || decoded.Gas.Gas != 30000 {
+		t.Errorf("unexpected decoded tx %s", out)
+	}
+	if code, _, errOut = thkcli("-endpoint", n.URL, "tx", "send", "-signed", txFile); code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	if n.param("SendTx", "nonce") != "9" {
+		t.Errorf("signed tx is not sent: %v", n.params["SendTx"])
+	}
+
+	if code, _, _ = thkcli("-endpoint", n.URL, "-chain", "1", "tx", "send", "-to", testContract); code != 1 {
+		t.Errorf("tx is sent without signer, exit %d", code)
+	}
+}
+
+func TestAbi(t *testing.T) {
+	dir, clean := tempDir(t)
+	defer clean()
+	abiFile := writeFile(t, filepath.Join(dir, "token.abi"), testABI)
+
+	code, out, errOut := thkcli("abi", "encode", "-abi", abiFile, "transfer", testContract, "0x10")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	var encoded map[string]string
+	if err := json.Unmarshal([]byte(out), &encoded); err != nil {
+		t.Fatal(err)
+	}
+	code, out, errOut = thkcli("abi", "decode", "-abi", abiFile, encoded["input"])
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	var decoded struct {
+		Name string `json:"name"`
+		Args []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"args"`
+	}
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Name != "transfer" || len(decoded.Args) != 2 || decoded.Args[0].Value != testContract || decoded.Args[1].Value != "16" {
+		t.Errorf("unexpected decoded input %s", out)
+	}
+
+	if code, _, _ = thkcli("abi", "encode", "-abi", abiFile, "transfer", testContract); code != 1 {
+		t.Errorf("encoded with missing argument, exit %d", code)
+	}
+	if code, _, _ = thkcli("abi", "encode", "-abi", abiFile, "approve", testContract, "1"); code != 1 {
+		t.Errorf("encoded unknown method, exit %d", code)
+	}
+}
+
+func TestContractCall(t *testing.T) {
+	dir, clean := tempDir(t)
+	defer clean()
+	abiFile := writeFile(t, filepath.Join(dir, "token.abi"), testABI)
+	n := newNode(t, map[string]interface{}{"CallTransaction": map[string]interface{}{
+		"status": 1, "gasUsed": 21000, "out": "0x" + strings.Repeat("0", 62) + "64",
+	}})
+	defer n.Close()
+
+	code, out, errOut := thkcli("-endpoint", n.URL, "-chain", "1", "-o", "table",
+		"contract", "call", "-abi", abiFile, "-to", testContract, "balanceOf", testContract)
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	if !strings.Contains(out, `[{"name":"","type":"uint256","value":"100"}]`) {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if input, _ := n.param("CallTransaction", "input").(string); !strings.HasSuffix(input, testContract[2:]) {
+		t.Errorf("unexpected input %s", input)
+	}
+}
+
+func TestKeystore(t *testing.T) {
+	dir, clean := tempDir(t)
+	defer clean()
+	passwordFile := writeFile(t, filepath.Join(dir, "password"), "secret")
+	keyFile := filepath.Join(dir, "key.json")
+
+	code, out, errOut := thkcli("-password-file", passwordFile, "keystore", "new", "-light", "-out", keyFile)
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	var created map[string]string
+	if err := json.Unmarshal([]byte(out), &created); err != nil {
+		t.Fatal(err)
+	}
+	code, out, errOut = thkcli("-password-file", passwordFile, "keystore", "inspect", keyFile)
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	if !strings.Contains(out, created["address"]) {
+		t.Errorf("inspected %s, created %s", out, created["address"])
+	}
+	if code, _, _ = thkcli("-password-file", passwordFile, "keystore", "new", "-out", keyFile); code != 1 {
+		t.Errorf("keystore is overwritten, exit %d", code)
+	}
+
+	wrong := writeFile(t, filepath.Join(dir, "wrong"), "wrong")
+	if code, _, _ = thkcli("-password-file", wrong, "keystore", "inspect", keyFile); code != 1 {
+		t.Errorf("keystore is decrypted by wrong password, exit %d", code)
+	}
+}
+
+func TestCheque(t *testing.T) {
+	dir, clean := tempDir(t)
+	defer clean()
+	n := newNode(t, map[string]interface{}{
+		"GetAccount":            map[string]interface{}{"nonce": 7},
+		"GetStats":              map[string]interface{}{"currentheight": 100},
+		"SendTx":                map[string]interface{}{"TXhash": "0xabcd"},
+		"RpcMakeVccProof":       map[string]interface{}{"input": testCashInput},
+		"MakeCCCExistenceProof": map[string]interface{}{"input": testCancelInput, "existence": false},
+	})
+	defer n.Close()
+	signer := signer(t, dir, n)
+	chequeFile := filepath.Join(dir, "cheque.json")
+
+	code, out, errOut := thkcli(append(signer, "cheque", "write", "-to-chain", "2", "-value", "1 TKM", "-out", chequeFile)...)
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	b, err := ioutil.ReadFile(chequeFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cheque := new(thk.CashCheque)
+	if err := json.Unmarshal(b, cheque); err != nil {
+		t.Fatal(err)
+	}
+	if cheque.Nonce != "7" || cheque.ToChainId != "2" || cheque.ExpireHeight != "1100" || cheque.Value != "1000000000000000000" {
+		t.Errorf("unexpected cheque %s", b)
+	}
+	if n.param("GetStats", "chainId") != "2" {
+		t.Errorf("expire height of chain %v", n.param("GetStats", "chainId"))
+	}
+	input, err := cheque.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.param("SendTx", "chainId") != "1" || n.param("SendTx", "toChainId") != "2" || n.param("SendTx", "nonce") != "7" ||
+		n.param("SendTx", "to") != thk.SystemContractAddressWithdraw || n.param("SendTx", "input") != input ||
+		n.param("SendTx", "value") != "0" {
+		t.Errorf("unexpected withdraw tx %v", n.params["SendTx"])
+	}
+	if !strings.Contains(out, `"hash": "0xabcd"`) {
+		t.Errorf("unexpected output %s", out)
+	}
+
+	code, _, errOut = thkcli(append(signer, "cheque", "cash", chequeFile)...)
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	if n.param("RpcMakeVccProof", "nonce") != "7" {
+		t.Errorf("unexpected proof request %v", n.params["RpcMakeVccProof"])
+	}
+	if n.param("SendTx", "chainId") != "2" || n.param("SendTx", "to") != thk.SystemContractAddressDeposit ||
+		n.param("SendTx", "input") != testCashInput {
+		t.Errorf("unexpected deposit tx %v", n.params["SendTx"])
+	}
+
+	code, _, errOut = thkcli(append(signer, "cheque", "cancel", chequeFile)...)
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	if n.param("MakeCCCExistenceProof", "chainId") != "2" {
+		t.Errorf("unexpected proof request %v", n.params["MakeCCCExistenceProof"])
+	}
+	if n.param("SendTx", "chainId") != "1" || n.param("SendTx", "to") != thk.SystemContractAddressCancel ||
+		n.param("SendTx", "input") != testCancelInput {
+		t.Errorf("unexpected cancel tx %v", n.params["SendTx"])
+	}
+
+	// the cheque is kept even if the withdraw is not sent
+	n.lock.Lock()
+	n.results["SendTx"] = errors.New("connection reset")
+	n.lock.Unlock()
+	lost := filepath.Join(dir, "lost.json")
+	if code, _, _ = thkcli(append(signer, "cheque", "write", "-to-chain", "2", "-value", "1 TKM", "-out", lost)...); code != 1 {
+		t.Errorf("withdraw is not failed, exit %d", code)
+	}
+	if _, err := os.Stat(lost); err != nil {
+		t.Errorf("cheque is not saved before sending: %v", err)
+	}
+	code, _, errOut = thkcli(append(signer, "cheque", "write", "-to-chain", "2", "-value", "1 TKM")...)
+	if code != 1 || !strings.Contains(errOut, `"expireheight": "1100"`) {
+		t.Errorf("cheque is not printed before sending, exit %d: %s", code, errOut)
+	}
+}
+
+func TestTxDecodeCheque(t *testing.T) {
+	dir, clean := tempDir(t)
+	defer clean()
+	cheque := &thk.CashCheque{ChainId: "1", FromChainId: "1", From: testContract, Nonce: "5", ToChainId: "2",
+		To: testContract, ExpireHeight: "1100", Value: "1000000000000000000"}
+	input, err := cheque.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := json.Marshal(&util.Transaction{ChainId: "1", FromChainId: "1", ToChainId: "2", From: testContract,
+		To: thk.SystemContractAddressWithdraw, Nonce: "5", Value: "0", Input: input})
+	if err != nil {
+		t.Fatal(err)
+	}
+	txFile := writeFile(t, filepath.Join(dir, "tx.json"), string(tx))
+
+	code, out, errOut := thkcli("-endpoint", "http://127.0.0.1:1", "tx", "decode", txFile)
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	var decoded struct {
+		Input struct {
+			Type   string                 `json:"type"`
+			Cheque map[string]interface{} `json:"cheque"`
+		} `json:"input"`
+	}
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Input.Type != thk.ChequeInputCheque.String() || decoded.Input.Cheque["Nonce"] != "5" ||
+		decoded.Input.Cheque["ExpireHeight"] != "1100" || decoded.Input.Cheque["Amount"] != "1000000000000000000" {
+		t.Errorf("unexpected decoded cheque %s", out)
+	}
+}
+
+func TestContractDeploy(t *testing.T) {
+	dir, clean := tempDir(t)
+	defer clean()
+	abiFile := writeFile(t, filepath.Join(dir, "token.abi"), "constructor(uint256 supply)\n"+testABI)
+	binFile := writeFile(t, filepath.Join(dir, "token.bin"), "6080\n")
+	n := newNode(t, map[string]interface{}{
+		"GetAccount": map[string]interface{}{"nonce": 7},
+		"SendTx":     map[string]interface{}{"TXhash": "0xabcd"},
+	})
+	defer n.Close()
+
+	code, out, errOut := thkcli(append(signer(t, dir, n), "contract", "deploy", "-abi", abiFile, "-bin", binFile, "1000")...)
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	if to := n.param("SendTx", "to"); to != nil && to != "" {
+		t.Errorf("deployment is sent to %v", to)
+	}
+	if n.param("SendTx", "input") != "0x6080"+strings.Repeat("0", 61)+"3e8" || n.param("SendTx", "nonce") != "7" {
+		t.Errorf("unexpected deploy tx %v", n.params["SendTx"])
+	}
+	var deployed map[string]string
+	if err := json.Unmarshal([]byte(out), &deployed); err != nil {
+		t.Fatal(err)
+	}
+	from, _ := n.param("SendTx", "from").(string)
+	if address, err := thk.ContractAddress(from, "7"); err != nil || deployed["address"] != address {
+		t.Errorf("contract address %s, want %s: %v", deployed["address"], address, err)
+	}
+}
+
+func TestBlockAndCommittee(t *testing.T) {
+	n := newNode(t, map[string]interface{}{
+		"GetBlock":       map[string]interface{}{"BlockHeader": map[string]interface{}{"height": 42}},
+		"GetBlockHeader": map[string]interface{}{"chainid": 1, "height": 42},
+		"GetCommittee":   []string{"0x01", "0x02"},
+	})
+	defer n.Close()
+
+	code, _, errOut := thkcli("-endpoint", n.URL, "-chain", "1", "block", "get", "42")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	if n.param("GetBlock", "chainId") != "1" || n.param("GetBlock", "height") != "42" {
+		t.Errorf("unexpected block request %v", n.params["GetBlock"])
+	}
+	code, out, errOut := thkcli("-endpoint", n.URL, "-chain", "1", "block", "get", "-header", "42")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	if n.param("GetBlockHeader", "height") != "42" || !strings.Contains(out, `"height": 42`) {
+		t.Errorf("unexpected header %s of %v", out, n.params["GetBlockHeader"])
+	}
+
+	code, out, errOut = thkcli("-endpoint", n.URL, "-chain", "1", "-o", "table", "committee", "7")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	if out != "0x01\n0x02\n" || n.param("GetCommittee", "chainId") != "1" || n.param("GetCommittee", "epoch") != "7" {
+		t.Errorf("unexpected committee %q of %v", out, n.params["GetCommittee"])
+	}
+}
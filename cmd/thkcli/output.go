@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/ThinkiumGroup/web3.go/common/hexutil"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// print writes the value in the output format. In tables, an object is printed as the rows of
+// its fields, a list of objects as the rows of the objects, and nested values as JSON.
+func (c *cli) print(v interface{}) error {
+	if c.output == "json" {
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(c.stdout, "%s\n", b)
+		return err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	switch value := generic.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(value) {
+			fmt.Fprintf(w, "%s\t%s\n", key, cell(value[key]))
+		}
+	case []interface{}:
+		printRows(w, value)
+	default:
+		fmt.Fprintln(w, cell(value))
+	}
+	return w.Flush()
+}
+
+// printRows prints the objects in columns of their keys, or other values one per line
+func printRows(w io.Writer, list []interface{}) {
+	columns := make(map[string]bool)
+	for _, item := range list {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			for _, item := range list {
+				fmt.Fprintln(w, cell(item))
+			}
+			return
+		}
+		for key := range object {
+			columns[key] = true
+		}
+	}
+	keys := sortedKeys(columns)
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(keys, "\t")))
+	for _, item := range list {
+		object := item.(map[string]interface{})
+		cells := make([]string, len(keys))
+		for i, key := range keys {
+			cells[i] = cell(object[key])
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+}
+
+func cell(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case bool:
+		return fmt.Sprint(value)
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func sortedKeys(m interface{}) []string {
+	keys := reflect.ValueOf(m).MapKeys()
+	ret := make([]string, len(keys))
+	for i, key := range keys {
+		ret[i] = key.String()
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// displayValue converts the values decoded by the abi for printing: bytes and addresses in hex,
+// integers in decimal strings and tuples in objects of their fields
+func displayValue(v interface{}) interface{} {
+	if n, ok := v.(*big.Int); ok {
+		return n.String()
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if !rv.IsNil() {
+			return displayValue(rv.Elem().Interface())
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprint(v)
+	case reflect.Array, reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return hexutil.Encode(b)
+		}
+		list := make([]interface{}, rv.Len())
+		for i := range list {
+			list[i] = displayValue(rv.Index(i).Interface())
+		}
+		return list
+	case reflect.Struct:
+		fields := make(map[string]interface{}, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			if name, ok := rv.Type().Field(i).Tag.Lookup("json"); ok {
+				fields[name] = displayValue(rv.Field(i).Interface())
+			} else {
+				fields[rv.Type().Field(i).Name] = displayValue(rv.Field(i).Interface())
+			}
+		}
+		return fields
+	}
+	return v
+}
+
+// readFile reads the file, or stdin if the path is "-"
+func readFile(path string, stdin io.Reader) ([]byte, error) {
+	if path == "-" && stdin != nil {
+		return ioutil.ReadAll(stdin)
+	}
+	return ioutil.ReadFile(path)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"github.com/ThinkiumGroup/web3.go/common/units"
+	"strconv"
+)
+
+func (c *cli) account(args []string) error {
+	fs := c.flags("account")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+	client, chainId, err := c.query()
+	if err != nil {
+		return err
+	}
+	account, err := client.Thk.GetAccount(fs.Arg(0), chainId)
+	if err != nil {
+		return err
+	}
+	return c.print(account)
+}
+
+func (c *cli) balance(args []string) error {
+	fs := c.flags("balance")
+	unitName := fs.String("unit", units.TKM.Name, "unit of the formatted balance")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+	unit, err := units.LookupUnit(*unitName)
+	if err != nil {
+		return err
+	}
+	client, chainId, err := c.query()
+	if err != nil {
+		return err
+	}
+	balance, err := client.Thk.GetBalance(fs.Arg(0), chainId)
+	if err != nil {
+		return err
+	}
+	return c.print(map[string]string{
+		"address":   fs.Arg(0),
+		"chainId":   chainId,
+		"wei":       balance.String(),
+		"formatted": units.FormatWithUnit(balance, unit),
+	})
+}
+
+func (c *cli) nonce(args []string) error {
+	fs := c.flags("nonce")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+	client, chainId, err := c.query()
+	if err != nil {
+		return err
+	}
+	nonce, err := client.Thk.GetNonce(fs.Arg(0), chainId)
+	if err != nil {
+		return err
+	}
+	return c.print(map[string]interface{}{"address": fs.Arg(0), "chainId": chainId, "nonce": nonce})
+}
+
+func (c *cli) stats(args []string) error {
+	fs := c.flags("stats")
+	if err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	client, chainId, err := c.query()
+	if err != nil {
+		return err
+	}
+	stats, err := client.Thk.GetStats(chainId)
+	if err != nil {
+		return err
+	}
+	return c.print(stats)
+}
+
+func (c *cli) committee(args []string) error {
+	fs := c.flags("committee")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+	if _, err := strconv.ParseUint(fs.Arg(0), 10, 64); err != nil {
+		return errUsage
+	}
+	client, chainId, err := c.query()
+	if err != nil {
+		return err
+	}
+	members, err := client.Thk.GetCommittee(chainId, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return c.print(members)
+}
+
+func (c *cli) block(args []string) error {
+	_, args, err := subcommand(args, "get")
+	if err != nil {
+		return err
+	}
+	fs := c.flags("block get")
+	header := fs.Bool("header", false, "get the header only")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+	if _, err := strconv.ParseUint(fs.Arg(0), 10, 64); err != nil {
+		return errUsage
+	}
+	client, chainId, err := c.query()
+	if err != nil {
+		return err
+	}
+	if *header {
+		res, err := client.Thk.GetBlockHeader(chainId, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		return c.print(res)
+	}
+	res, err := client.Thk.GetBlock(chainId, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return c.print(res)
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ThinkiumGroup/web3.go/common"
+	"github.com/ThinkiumGroup/web3.go/common/hexutil"
+	"github.com/ThinkiumGroup/web3.go/common/units"
+	"github.com/ThinkiumGroup/web3.go/web3"
+	"github.com/ThinkiumGroup/web3.go/web3/keystore"
+	"github.com/ThinkiumGroup/web3.go/web3/thk"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// txFlags are the flags of the commands sending transactions
+type txFlags struct {
+	nonce    string
+	gas      uint64
+	gasPrice string
+	estimate bool
+}
+
+func addTxFlags(fs *flag.FlagSet) *txFlags {
+	f := new(txFlags)
+	fs.StringVar(&f.nonce, "nonce", "", "nonce of the transaction, the nonce of the account by default")
+	fs.Uint64Var(&f.gas, "gas", 0, "gas limit, the default of the node if 0")
+	fs.StringVar(&f.gasPrice, "gas-price", "", "gas price in Wei or with a unit, such as 4 GWei")
+	fs.BoolVar(&f.estimate, "estimate-gas", false, "estimate the gas and fetch the gas price of the chain")
+	return f
+}
+
+// parseValue parses the amount in Wei or with a unit, such as "1.5 TKM"
+func parseValue(s string) (string, error) {
+	if s == "" {
+		return "0", nil
+	}
+	value, err := units.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return value.String(), nil
+}
+
+// newTx returns the transaction of the signer on the chain
+func (c *cli) newTx(to, value, input string) (*web3.Web3, *keystore.Key, *util.Transaction, error) {
+	client, chainId, err := c.query()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if value, err = parseValue(value); err != nil {
+		return nil, nil, nil, err
+	}
+	key, err := c.signer()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	tx := &util.Transaction{ChainId: chainId, FromChainId: chainId, ToChainId: chainId, From: key.Address,
+		To: to, Value: value, Input: input}
+	return client, key, tx, nil
+}
+
+// sign sets the nonce and the gas of the transaction by the flags, and signs it by the key
+func (f *txFlags) sign(client *web3.Web3, key *keystore.Key, tx *util.Transaction) error {
+	switch {
+	case f.nonce != "":
+		if _, err := strconv.ParseUint(f.nonce, 10, 64); err != nil {
+			return fmt.Errorf("invalid nonce %q", f.nonce)
+		}
+		tx.Nonce = f.nonce
+	case tx.Nonce == "":
+		nonce, err := client.Thk.GetNonce(tx.From, tx.ChainId)
+		if err != nil {
+			return err
+		}
+		tx.Nonce = strconv.FormatInt(nonce, 10)
+	}
+	switch {
+	case f.estimate:
+		if _, err := client.Thk.FillGas(tx); err != nil {
+			return err
+		}
+	case f.gas > 0 || f.gasPrice != "":
+		gasProvider := &util.GasProvider{Gas: f.gas, GasPrice: util.DefaultGasProvider.GasPrice}
+		if gasProvider.Gas == 0 {
+			gasProvider.Gas = util.DefaultGasProvider.Gas
+		}
+		if f.gasPrice != "" {
+			price, err := units.Parse(f.gasPrice)
+			if err != nil {
+				return err
+			}
+			gasProvider.GasPrice = price
+		}
+		if err := tx.SetGasProvider(gasProvider); err != nil {
+			return err
+		}
+	}
+	return client.Thk.SignTransaction(tx, key.PrivateKey)
+}
+
+func (c *cli) send(client *web3.Web3, tx *util.Transaction) error {
+	hash, err := client.Thk.SendTx(tx)
+	if err != nil {
+		return err
+	}
+	return c.print(map[string]string{"chainId": tx.ChainId, "from": tx.From, "nonce": tx.Nonce, "hash": hash})
+}
+
+func (c *cli) tx(args []string) error {
+	name, args, err := subcommand(args, "send", "sign", "decode", "get")
+	if err != nil {
+		return err
+	}
+	switch name {
+	case "decode":
+		return c.txDecode(args)
+	case "get":
+		return c.txGet(args)
+	}
+	fs := c.flags("tx " + name)
+	to := fs.String("to", "", "address of the receiver or the contract")
+	value := fs.String("value", "", "amount in Wei or with a unit, such as 1.5 TKM")
+	input := fs.String("input", "", "input in hex")
+	signed := fs.String("signed", "", "send the transaction signed by tx sign in the file, or - for stdin")
+	f := addTxFlags(fs)
+	if err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *signed != "" {
+		if name != "send" {
+			return errUsage
+		}
+		tx, err := c.readTx(*signed)
+		if err != nil {
+			return err
+		}
+		client, err := c.web3()
+		if err != nil {
+			return err
+		}
+		return c.send(client, tx)
+	}
+	if !isAddress(*to) {
+		return fmt.Errorf("%w: invalid -to %q", errUsage, *to)
+	}
+	client, key, tx, err := c.newTx(*to, *value, *input)
+	if err != nil {
+		return err
+	}
+	if err := f.sign(client, key, tx); err != nil {
+		return err
+	}
+	if name == "sign" {
+		return c.print(tx)
+	}
+	return c.send(client, tx)
+}
+
+func (c *cli) readTx(path string) (*util.Transaction, error) {
+	b, err := readFile(path, c.stdin)
+	if err != nil {
+		return nil, err
+	}
+	tx := new(util.Transaction)
+	if err := json.Unmarshal(b, tx); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return tx, nil
+}
+
+// txDecode prints the signing hash of the transaction, and decodes its input by the abi, or as a
+// cheque if it's sent to a system contract
+func (c *cli) txDecode(args []string) error {
+	fs := c.flags("tx decode")
+	abiFile := fs.String("abi", "", "abi file of the contract called by the transaction")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+	tx, err := c.readTx(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	network, err := c.network()
+	if err != nil {
+		return err
+	}
+	hash, err := tx.HashValue(network)
+	if err != nil {
+		return err
+	}
+	ret := map[string]interface{}{"tx": tx, "signingHash": hexutil.Encode(hash), "network": network.String()}
+	if gasProvider, err := tx.GasProvider(); err == nil && tx.Extra != "" {
+		ret["gas"] = gasProvider
+	}
+	contracts := network.SystemContracts
+	switch {
+	case tx.Input == "" || tx.Input == "0x":
+	case *abiFile != "":
+		contract, err := c.loadContract(*abiFile)
+		if err != nil {
+			return err
+		}
+		decoded, err := contract.DecodeInput(tx.Input)
+		if err != nil {
+			return err
+		}
+		ret["input"] = decodedInput(decoded)
+	case sameAddress(tx.To, contracts.Withdraw), sameAddress(tx.To, contracts.Deposit), sameAddress(tx.To, contracts.Cancel):
+		typ, check, err := thk.DecodeChequeInput(tx.Input)
+		if err != nil {
+			return err
+		}
+		ret["input"] = map[string]interface{}{"type": typ.String(), "cheque": displayValue(check)}
+	}
+	return c.print(ret)
+}
+
+func isAddress(s string) bool {
+	return common.IsStrictAddress(strings.ToLower(s))
+}
+
+func sameAddress(a, b string) bool {
+	return isAddress(a) && common.HexToAddress(a) == common.HexToAddress(b)
+}
+
+func (c *cli) txGet(args []string) error {
+	fs := c.flags("tx get")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+	client, chainId, err := c.query()
+	if err != nil {
+		return err
+	}
+	res, err := client.Thk.GetTransactionByHash(chainId, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return c.print(res)
+}
+
+func (c *cli) cheque(args []string) error {
+	name, args, err := subcommand(args, "write", "cash", "cancel")
+	if err != nil {
+		return err
+	}
+	if name == "write" {
+		return c.chequeWrite(args)
+	}
+	fs := c.flags("cheque " + name)
+	f := addTxFlags(fs)
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+	b, err := readFile(fs.Arg(0), c.stdin)
+	if err != nil {
+		return err
+	}
+	cheque := new(thk.CashCheque)
+	if err := json.Unmarshal(b, cheque); err != nil {
+		return fmt.Errorf("%s: %v", fs.Arg(0), err)
+	}
+	client, err := c.web3()
+	if err != nil {
+		return err
+	}
+	key, err := c.signer()
+	if err != nil {
+		return err
+	}
+	var tx *util.Transaction
+	if name == "cash" {
+		proof, err := client.Thk.RpcMakeVccProof(cheque)
+		if err != nil {
+			return err
+		}
+		if tx, err = proof.DepositTransaction(key.Address, client.Thk.Network.SystemContracts); err != nil {
+			return err
+		}
+	} else {
+		cheque.ChainId = cheque.ToChainId
+		proof, err := client.Thk.MakeCCCExistenceProof(cheque)
+		if err != nil {
+			return err
+		}
+		if tx, err = proof.CancelTransaction(key.Address, client.Thk.Network.SystemContracts); err != nil {
+			return err
+		}
+	}
+	if err := f.sign(client, key, tx); err != nil {
+		return err
+	}
+	return c.send(client, tx)
+}
+
+// chequeWrite saves the cheque for cashing or cancelling to -out, or prints it to stderr, and then
+// sends the withdraw transaction
+func (c *cli) chequeWrite(args []string) error {
+	fs := c.flags("cheque write")
+	to := fs.String("to", "", "address of the receiver, the signer by default")
+	toChain := fs.String("to-chain", "", "chain id of the receiver")
+	value := fs.String("value", "", "amount in Wei or with a unit, such as 1.5 TKM")
+	expire := fs.Int("expire", 1000, "blocks of the to chain before the cheque expires")
+	out := fs.String("out", "", "file to save the cheque")
+	f := addTxFlags(fs)
+	if err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if _, err := strconv.ParseUint(*toChain, 10, 32); err != nil {
+		return fmt.Errorf("%w: invalid -to-chain %q", errUsage, *toChain)
+	}
+	if *expire <= 0 {
+		return fmt.Errorf("%w: invalid -expire %d", errUsage, *expire)
+	}
+	client, key, tx, err := c.newTx("", *value, "")
+	if err != nil {
+		return err
+	}
+	if *to == "" {
+		*to = key.Address
+	}
+	if !isAddress(*to) {
+		return fmt.Errorf("%w: invalid -to %q", errUsage, *to)
+	}
+	if tx.Value == "0" {
+		return errors.New("no value of the cheque")
+	}
+	stats, err := client.Thk.GetStats(*toChain)
+	if err != nil {
+		return err
+	}
+	if f.nonce == "" {
+		nonce, err := client.Thk.GetNonce(tx.From, tx.ChainId)
+		if err != nil {
+			return err
+		}
+		f.nonce = strconv.FormatInt(nonce, 10)
+	}
+	cheque := &thk.CashCheque{
+		ChainId: tx.ChainId, FromChainId: tx.ChainId, From: tx.From, Nonce: f.nonce, ToChainId: *toChain,
+		To: *to, ExpireHeight: strconv.Itoa(stats.CurrentHeight + *expire), Value: tx.Value,
+	}
+	if tx.Input, err = cheque.Encode(); err != nil {
+		return err
+	}
+	tx.ToChainId, tx.To, tx.Value = *toChain, client.Thk.Network.SystemContracts.Withdraw, "0"
+	if err := f.sign(client, key, tx); err != nil {
+		return err
+	}
+	// the cheque is kept before sending, it's needed to cash or cancel the withdrawn value even if
+	// the response of SendTx is lost
+	b, err := json.MarshalIndent(cheque, "", "  ")
+	if err != nil {
+		return err
+	}
+	if *out != "" {
+		if err := ioutil.WriteFile(*out, b, 0600); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(c.stderr, "cheque:\n%s\n", b)
+	}
+	hash, err := client.Thk.SendTx(tx)
+	if err != nil {
+		return err
+	}
+	return c.print(map[string]interface{}{"hash": hash, "cheque": cheque})
+}
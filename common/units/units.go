@@ -0,0 +1,168 @@
+// Package units converts TKM amounts between the denominations. Amounts are exact big.Int
+// values in Wei, the smallest denomination, and fractions are computed by big.Rat, so no
+// precision is lost by floating-point numbers.
+package units
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Unit is a denomination of TKM, 1 of the unit is 10^Decimals Wei
+type Unit struct {
+	Name     string
+	Decimals int
+}
+
+var (
+	Wei    = Unit{Name: "Wei", Decimals: 0}
+	KWei   = Unit{Name: "KWei", Decimals: 3}
+	MWei   = Unit{Name: "MWei", Decimals: 6}
+	GWei   = Unit{Name: "GWei", Decimals: 9}
+	Szabo  = Unit{Name: "Szabo", Decimals: 12}
+	Finney = Unit{Name: "Finney", Decimals: 15}
+	TKM    = Unit{Name: "TKM", Decimals: 18}
+
+	Units = []Unit{Wei, KWei, MWei, GWei, Szabo, Finney, TKM}
+)
+
+// LookupUnit finds the unit by its name case-insensitively
+func LookupUnit(name string) (Unit, error) {
+	for _, u := range Units {
+		if strings.EqualFold(u.Name, name) {
+			return u, nil
+		}
+	}
+	return Unit{}, fmt.Errorf("unknown unit %q", name)
+}
+
+// Multiplier returns 10^Decimals
+func (u Unit) Multiplier() *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(u.Decimals)), nil)
+}
+
+func (u Unit) String() string {
+	return u.Name
+}
+
+// ToRat returns the amount in Wei as the exact number in the unit
+func ToRat(wei *big.Int, unit Unit) *big.Rat {
+	if wei == nil {
+		return new(big.Rat)
+	}
+	return new(big.Rat).SetFrac(wei, unit.Multiplier())
+}
+
+// FromRat converts the number in the unit to Wei, which fails if it's not an integer in Wei
+func FromRat(r *big.Rat, unit Unit) (*big.Int, error) {
+	wei := new(big.Rat).Mul(r, new(big.Rat).SetInt(unit.Multiplier()))
+	if !wei.IsInt() {
+		return nil, fmt.Errorf("%s %s is less than 1 Wei", r.FloatString(unit.Decimals+1), unit)
+	}
+	return new(big.Int).Set(wei.Num()), nil
+}
+
+// ParseUnit parses the decimal number in the unit, such as "1.5", to Wei
+func ParseUnit(s string, unit Unit) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, "/eE") {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	return FromRat(r, unit)
+}
+
+// Parse parses the amount with an optional unit, such as "1.5 TKM", "1.5tkm" or "1500", to Wei.
+// The number is in Wei if there's no unit.
+func Parse(s string) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	i := strings.LastIndexAny(s, "0123456789.") + 1
+	unit := Wei
+	if name := strings.TrimSpace(s[i:]); name != "" {
+		var err error
+		if unit, err = LookupUnit(name); err != nil {
+			return nil, err
+		}
+	}
+	return ParseUnit(s[:i], unit)
+}
+
+// MustParse is Parse panicking on errors, for constants
+func MustParse(s string) *big.Int {
+	wei, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return wei
+}
+
+// Format formats the amount in Wei as the exact decimal number in the unit without trailing zeros,
+// such as "1.5" for 1500000000000000000 Wei in TKM
+func Format(wei *big.Int, unit Unit) string {
+	s := ToRat(wei, unit).FloatString(unit.Decimals)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// FormatWithUnit is Format followed by the name of the unit, such as "1.5 TKM"
+func FormatWithUnit(wei *big.Int, unit Unit) string {
+	return Format(wei, unit) + " " + unit.Name
+}
+
+// Amount is an amount of TKM in Wei, which is encoded in JSON as a string in TKM such as "1.5 TKM".
+// It's decoded from a string with an optional unit, or a JSON number in Wei.
+type Amount struct {
+	big.Int
+}
+
+// NewAmount returns the amount of the Wei
+func NewAmount(wei *big.Int) *Amount {
+	a := new(Amount)
+	if wei != nil {
+		a.Set(wei)
+	}
+	return a
+}
+
+// Wei returns the amount in Wei
+func (a *Amount) Wei() *big.Int {
+	return new(big.Int).Set(&a.Int)
+}
+
+func (a *Amount) String() string {
+	return FormatWithUnit(&a.Int, TKM)
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("empty amount")
+	}
+	if data[0] != '"' {
+		if _, ok := a.SetString(string(data), 10); !ok {
+			return fmt.Errorf("invalid amount %s", data)
+		}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	wei, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	a.Set(wei)
+	return nil
+}
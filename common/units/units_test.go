@@ -0,0 +1,105 @@
+package units
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		s   string
+		wei string
+	}{
+		{"1.5 TKM", "1500000000000000000"},
+		{"1.5tkm", "1500000000000000000"},
+		{" 0.000000000000000001 TKM ", "1"},
+		{"123456789.123456789123456789 TKM", "123456789123456789123456789"},
+		{"-2 Finney", "-2000000000000000"},
+		{"40 GWei", "40000000000"},
+		{"1500", "1500"},
+		{"1500 wei", "1500"},
+		{".5 KWei", "500"},
+	}
+	for _, test := range tests {
+		wei, err := Parse(test.s)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", test.s, err)
+			continue
+		}
+		if wei.String() != test.wei {
+			t.Errorf("Parse(%q)=%s, want %s", test.s, wei, test.wei)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, s := range []string{"", "TKM", "1.5", "0.0000000000000000001 TKM", "1 THK", "1e18", "1/2 TKM", "1..5 TKM"} {
+		if wei, err := Parse(s); err == nil {
+			t.Errorf("Parse(%q)=%s, want error", s, wei)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		wei  string
+		unit Unit
+		r    string
+	}{
+		{"1500000000000000000", TKM, "1.5"},
+		{"1000000000000000000", TKM, "1"},
+		{"1", TKM, "0.000000000000000001"},
+		{"-1500000000000000000", TKM, "-1.5"},
+		{"0", TKM, "0"},
+		{"40000000000", GWei, "40"},
+		{"1500", Wei, "1500"},
+	}
+	for _, test := range tests {
+		wei, _ := new(big.Int).SetString(test.wei, 10)
+		if r := Format(wei, test.unit); r != test.r {
+			t.Errorf("Format(%s, %s)=%s, want %s", test.wei, test.unit, r, test.r)
+		}
+		back, err := ParseUnit(test.r, test.unit)
+		if err != nil || back.Cmp(wei) != 0 {
+			t.Errorf("ParseUnit(%s, %s)=%s, %v, want %s", test.r, test.unit, back, err, test.wei)
+		}
+	}
+	if s := FormatWithUnit(MustParse("2.25 TKM"), TKM); s != "2.25 TKM" {
+		t.Errorf("FormatWithUnit=%s", s)
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	type payment struct {
+		Amount *Amount `json:"amount"`
+	}
+	p := payment{Amount: NewAmount(MustParse("1.5 TKM"))}
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"amount":"1.5 TKM"}` {
+		t.Errorf("encoded as %s", b)
+	}
+	var back payment
+	if err = json.Unmarshal(b, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Amount.Cmp(&p.Amount.Int) != 0 {
+		t.Errorf("decoded as %s", back.Amount.Wei())
+	}
+	for data, wei := range map[string]string{
+		`{"amount":1500000000000000000}`: "1500000000000000000",
+		`{"amount":"250 GWei"}`:          "250000000000",
+		`{"amount":"7"}`:                 "7",
+	} {
+		var p payment
+		if err = json.Unmarshal([]byte(data), &p); err != nil || p.Amount.Wei().String() != wei {
+			t.Errorf("Unmarshal(%s)=%v, %v, want %s", data, p.Amount, err, wei)
+		}
+	}
+	if err = json.Unmarshal([]byte(`{"amount":"1.5"}`), &back); err == nil {
+		t.Error("fractional Wei is decoded")
+	}
+}
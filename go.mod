@@ -7,4 +7,7 @@ require (
 	github.com/ThinkiumGroup/go-common v1.3.25
 	github.com/stephenfire/go-rtl v1.0.2
 	github.com/stretchr/testify v1.4.0
+	golang.org/x/crypto v0.0.0-20211117183948-ae814b36b871
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+	sigs.k8s.io/yaml v1.2.0
 )
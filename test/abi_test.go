@@ -156,3 +156,153 @@ func TestDecodeError(t *testing.T) {
 	decode, _ := hexutil.Decode("0x7bf0a4401d3ecd7eb")
 	fmt.Println(string(decode))
 }
+
+func TestDecodeCustomError(t *testing.T) {
+	const abiJSON = `[{"inputs":[{"internalType":"uint256","name":"available","type":"uint256"},{"internalType":"uint256","name":"required","type":"uint256"}],"name":"InsufficientBalance","type":"error"},{"inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"type":"function"}]`
+	abi, err := abi2.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	abiErr, ok := abi.Errors["InsufficientBalance"]
+	if !ok {
+		t.Fatal("error InsufficientBalance not parsed")
+	}
+	if abiErr.Sig() != "InsufficientBalance(uint256,uint256)" {
+		t.Fatalf("unexpected signature: %s", abiErr.Sig())
+	}
+	args, err := abiErr.Inputs.Pack(big.NewInt(10), big.NewInt(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err := abi.ErrorById(abiErr.Id())
+	if err != nil || found.Name != abiErr.Name {
+		t.Fatalf("ErrorById failed: %v", err)
+	}
+	res, err := abi.UnpackError(append(abiErr.Id(), args...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Name != "InsufficientBalance" || res.Args["available"].(*big.Int).Int64() != 10 ||
+		res.Args["required"].(*big.Int).Int64() != 20 {
+		t.Fatalf("unexpected contract error: %+v", res)
+	}
+	t.Log(res.Error())
+
+	output := "0x08c379a000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000012496e73756666696369656e742066756e64730000000000000000000000000000"
+	res, err = abi.UnpackError(hexutil.MustDecode(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Reason != "Insufficient funds" {
+		t.Fatalf("unexpected revert reason: %s", res.Reason)
+	}
+}
+
+func TestDecodePanic(t *testing.T) {
+	output := abi2.PanicMethodId + "0000000000000000000000000000000000000000000000000000000000000011"
+	res, err := abi2.UnpackRevert(hexutil.MustDecode(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Name != "Panic" || res.PanicCode.Int64() != 0x11 || res.Reason != "arithmetic underflow or overflow" {
+		t.Fatalf("unexpected panic error: %+v", res)
+	}
+	t.Log(res.Error())
+}
+
+func TestParseHumanReadable(t *testing.T) {
+	const abiJSON = `[{"inputs":[{"name":"symbol","type":"string"},{"name":"decimals","type":"uint8"}],"type":"constructor"},{"inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"components":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"name":"orders","type":"tuple[]"}],"name":"batch","outputs":[],"type":"function"},{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"},{"inputs":[{"name":"available","type":"uint256"}],"name":"InsufficientBalance","type":"error"}]`
+	signatures := []string{
+		"constructor(string symbol, uint8 decimals)",
+		"function transfer(address to, uint amount) external returns (bool)",
+		"function balanceOf(address owner) view returns (uint256)",
+		"function batch(tuple(address to, uint256 amount)[] calldata orders)",
+		"event Transfer(address indexed from, address indexed to, uint256 value)",
+		"error InsufficientBalance(uint256 available)",
+	}
+	expected, err := abi2.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := abi2.ParseHumanReadable(signatures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Constructor.Sig() != expected.Constructor.Sig() {
+		t.Errorf("constructor mismatch: %s != %s", parsed.Constructor.Sig(), expected.Constructor.Sig())
+	}
+	if len(parsed.Methods) != len(expected.Methods) || len(parsed.Events) != len(expected.Events) ||
+		len(parsed.Errors) != len(expected.Errors) {
+		t.Fatalf("parsed abi mismatch: %+v", parsed)
+	}
+	for name, method := range expected.Methods {
+		if parsed.Methods[name].Sig() != method.Sig() || parsed.Methods[name].Const != method.Const {
+			t.Errorf("method %s mismatch: %s != %s", name, parsed.Methods[name], method)
+		}
+		// String() renders back to the human-readable form
+		reparsed, err := abi2.ParseHumanReadable([]string{method.String()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reparsed.Methods[name].String() != method.String() {
+			t.Errorf("round trip of %s failed: %s", method, reparsed.Methods[name])
+		}
+	}
+	if parsed.Events["Transfer"].String() != signatures[4] {
+		t.Errorf("event mismatch: %s", parsed.Events["Transfer"])
+	}
+	if parsed.Events["Transfer"].Id() != expected.Events["Transfer"].Id() {
+		t.Errorf("event id mismatch")
+	}
+	if parsed.Errors["InsufficientBalance"].String() != signatures[5] {
+		t.Errorf("error mismatch: %s", parsed.Errors["InsufficientBalance"])
+	}
+	if s := parsed.Methods["transfer"].String(); s != "function transfer(address to, uint256 amount) returns (bool)" {
+		t.Errorf("unexpected method string: %s", s)
+	}
+
+	if _, err = abi2.ParseHumanReadable([]string{"function transfer(address to,"}); err == nil {
+		t.Error("malformed signature should fail")
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	parsed, err := abi2.ParseHumanReadable([]string{
+		"function f(address to, uint256 amount, int8 delta, bool ok, bytes32 id, uint16[] list, (address to, uint64 n) pair, string s)",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputs := parsed.Methods["f"].Inputs
+	args, err := inputs.ParseArgs([]string{
+		"0x0e50cea0402d2a396b0db1c5d08155bd219cc52e", "0x10", "-128", "true", "0x" + strings.Repeat("ab", 32),
+		`[1, "0x2"]`, `{"to": "0x0e50cea0402d2a396b0db1c5d08155bd219cc52e", "n": 3}`, "text",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args[1].(*big.Int).Int64() != 16 || args[2].(int8) != -128 || !args[3].(bool) || args[7].(string) != "text" {
+		t.Errorf("unexpected args %v", args)
+	}
+	if list := args[5].([]uint16); len(list) != 2 || list[1] != 2 {
+		t.Errorf("unexpected list %v", args[5])
+	}
+	// the parsed values are packed as the values of their types
+	if _, err := inputs.Pack(args...); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, values := range [][]string{
+		{"0x0e50cea0402d2a396b0db1c5d08155bd219cc52e"},
+		{"0x0e50", "1", "0", "true", "0x00", "[]", "{}", ""},
+		{"0x0e50cea0402d2a396b0db1c5d08155bd219cc52e", "-1", "0", "true", "0x" + strings.Repeat("ab", 32), "[]", `{"to": "0x0e50cea0402d2a396b0db1c5d08155bd219cc52e", "n": 3}`, ""},
+		{"0x0e50cea0402d2a396b0db1c5d08155bd219cc52e", "1", "128", "true", "0x" + strings.Repeat("ab", 32), "[]", `{"to": "0x0e50cea0402d2a396b0db1c5d08155bd219cc52e", "n": 3}`, ""},
+		{"0x0e50cea0402d2a396b0db1c5d08155bd219cc52e", "1", "0", "yes", "0x" + strings.Repeat("ab", 32), "[]", `{"to": "0x0e50cea0402d2a396b0db1c5d08155bd219cc52e", "n": 3}`, ""},
+		{"0x0e50cea0402d2a396b0db1c5d08155bd219cc52e", "1", "0", "true", "0x" + strings.Repeat("ab", 32), "[65536]", `{"to": "0x0e50cea0402d2a396b0db1c5d08155bd219cc52e", "n": 3}`, ""},
+		{"0x0e50cea0402d2a396b0db1c5d08155bd219cc52e", "1", "0", "true", "0x" + strings.Repeat("ab", 32), "[]", `{"to": "0x0e50cea0402d2a396b0db1c5d08155bd219cc52e"}`, ""},
+	} {
+		if _, err := inputs.ParseArgs(values); err == nil {
+			t.Errorf("%v is parsed", values)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/ThinkiumGroup/web3.go/web3/providers"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newCountingCache(t *testing.T, counting *fakeProvider, opts providers.CacheOptions) *providers.CachingProvider {
+	cache, err := providers.NewCachingProvider(counting, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cache
+}
+
+func heightParams(height string) map[string]string {
+	return map[string]string{"chainId": "1", "height": height}
+}
+
+func TestCacheRules(t *testing.T) {
+	counting := &fakeProvider{delay: 5 * time.Millisecond, result: respond(func(n int, params interface{}) (interface{}, error) {
+		return map[string]int{"n": n}, nil
+	})}
+	head := func(chainId string) (uint64, error) { return 100, nil }
+	cache := newCountingCache(t, counting, providers.CacheOptions{Rules: map[string]providers.CacheRule{
+		"GetBlock":             providers.ParamBelowHead("height", head, 10),
+		"GetTransactionByHash": providers.Immutable,
+	}})
+	get := func(method string, params interface{}) int {
+		var res map[string]int
+		if err := cache.SendRequest(&res, method, params); err != nil {
+			t.Fatal(err)
+		}
+		return res["n"]
+	}
+	if a, b := get("GetBlock", heightParams("90")), get("GetBlock", heightParams("90")); a != b {
+		t.Errorf("block 10 below head is not cached: %d %d", a, b)
+	}
+	if a, b := get("GetBlock", heightParams("91")), get("GetBlock", heightParams("91")); a == b {
+		t.Error("block 9 below head is cached")
+	}
+	if a, b := get("GetTransactionByHash", map[string]string{"hash": "0x01"}), get("GetTransactionByHash", map[string]string{"hash": "0x01"}); a != b {
+		t.Error("transaction is not cached")
+	}
+	if a, b := get("GetStats", nil), get("GetStats", nil); a == b {
+		t.Error("method without rule is cached")
+	}
+	if hits, misses := cache.Stats(); hits != 2 || misses != 4 {
+		t.Errorf("unexpected hits %d and misses %d", hits, misses)
+	}
+}
+
+func TestCacheSkipsErrors(t *testing.T) {
+	counting := &fakeProvider{delay: 5 * time.Millisecond, result: respond(func(n int, params interface{}) (interface{}, error) {
+		if n%2 == 0 {
+			return map[string]interface{}{"error": map[string]interface{}{"code": -32000, "message": "transaction not found"}}, nil
+		}
+		return map[string]string{"ErrMsg": "transaction not found"}, nil
+	})}
+	cache := newCountingCache(t, counting, providers.CacheOptions{Rules: map[string]providers.CacheRule{
+		"GetTransactionByHash": providers.Immutable,
+	}})
+	for i := 0; i < 3; i++ {
+		var res map[string]interface{}
+		_ = cache.SendRequest(&res, "GetTransactionByHash", map[string]string{"hash": "0x01"})
+	}
+	if len(counting.methods) != 3 {
+		t.Errorf("error result is cached")
+	}
+}
+
+func TestCacheSingleFlight(t *testing.T) {
+	counting := &fakeProvider{gate: make(chan struct{}), result: respond(func(n int, params interface{}) (interface{}, error) {
+		return map[string]int{"n": n}, nil
+	})}
+	cache := newCountingCache(t, counting, providers.CacheOptions{Rules: map[string]providers.CacheRule{
+		// not cacheable, but concurrent requests are still sent once
+		"GetBlock": func(params interface{}, result json.RawMessage) bool { return false },
+	}})
+	var wg sync.WaitGroup
+	results := make([]int, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var res map[string]int
+			_ = cache.SendRequest(&res, "GetBlock", heightParams("1"))
+			results[i] = res["n"]
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(counting.gate)
+	wg.Wait()
+	if len(counting.methods) != 1 {
+		t.Errorf("expect 1 request, but %d", len(counting.methods))
+	}
+	for _, n := range results {
+		if n != 1 {
+			t.Errorf("unexpected results %v", results)
+		}
+	}
+}
+
+func TestCacheEvictionAndPersistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "thkcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	counting := &fakeProvider{delay: 5 * time.Millisecond, result: struct{}{}}
+	rules := map[string]providers.CacheRule{"GetBlock": providers.Immutable}
+	memory := newCountingCache(t, counting, providers.CacheOptions{Size: 2, Rules: rules})
+	var res map[string]string
+	for _, h := range []string{"1", "2", "3", "1"} {
+		_ = memory.SendRequest(&res, "GetBlock", heightParams(h))
+	}
+	// block 1 is evicted by block 3
+	if len(counting.methods) != 4 {
+		t.Errorf("expect 4 requests, but %d", len(counting.methods))
+	}
+
+	counting = &fakeProvider{delay: 5 * time.Millisecond, result: struct{}{}}
+	disk := newCountingCache(t, counting, providers.CacheOptions{Size: 1, Rules: rules, Dir: dir})
+	for _, h := range []string{"1", "2", "1"} {
+		_ = disk.SendRequest(&res, "GetBlock", heightParams(h))
+	}
+	if len(counting.methods) != 2 {
+		t.Errorf("evicted result is not read from disk, %d requests", len(counting.methods))
+	}
+	if hits, misses := disk.Stats(); hits != 1 || misses != 2 {
+		t.Errorf("unexpected hits %d and misses %d of the disk", hits, misses)
+	}
+	reopened := newCountingCache(t, counting, providers.CacheOptions{Rules: rules, Dir: dir})
+	_ = reopened.SendRequest(&res, "GetBlock", heightParams("2"))
+	if len(counting.methods) != 2 {
+		t.Error("persisted result is not used after reopening")
+	}
+}
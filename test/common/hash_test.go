@@ -52,7 +52,7 @@ func TestHash(t *testing.T) {
 			t.Error(err.Error())
 			return
 		}
-		hashBytes, err := transaction.HashValue()
+		hashBytes, err := transaction.HashValue(nil)
 		if err != nil {
 			t.Error(err.Error())
 			return
@@ -0,0 +1,201 @@
+package test
+
+import (
+	"github.com/ThinkiumGroup/web3.go/web3"
+	"github.com/ThinkiumGroup/web3.go/web3/keystore"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const configYAML = `
+# test network
+endpoint:
+  address: https://test.thinkiumrpc.net   # default node
+  timeout: 5s
+  rateLimit: 50
+  burst: 10
+chains:
+  "2":
+    address: 127.0.0.1:8089
+    cache: {size: 100, dir: /tmp/thk}
+chainId: 1
+network:
+  name: testnet
+  baseChainId: 100007
+  chains: [0, 1, 2]
+signer:
+  keystore: "/etc/thk/key #1.json"
+  passwordEnv: KEY_PASSWORD
+extraSigners:
+- keystore: /etc/thk/multi1.json
+  passwordFile: /etc/thk/multi1.pass
+- keystore: '/etc/thk/multi2.json'
+retry:
+  maxRetries: 3
+  backoff: 0.5
+`
+
+const configJSON = `{
+  "endpoint": {"address": "https://test.thinkiumrpc.net", "timeout": "5s", "rateLimit": 50, "burst": 10},
+  "chains": {"2": {"address": "127.0.0.1:8089", "cache": {"size": 100, "dir": "/tmp/thk"}}},
+  "chainId": "1",
+  "network": {"name": "testnet", "baseChainId": 100007, "chains": [0, 1, 2]},
+  "signer": {"keystore": "/etc/thk/key #1.json", "passwordEnv": "KEY_PASSWORD"},
+  "extraSigners": [
+    {"keystore": "/etc/thk/multi1.json", "passwordFile": "/etc/thk/multi1.pass"},
+    {"keystore": "/etc/thk/multi2.json"}
+  ],
+  "retry": {"maxRetries": 3, "backoff": "500ms"}
+}`
+
+func TestParseConfig(t *testing.T) {
+	fromYAML, err := web3.ParseYAMLConfig([]byte(configYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := web3.ParseJSONConfig([]byte(configJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("configs are different\nyaml: %s\njson: %s", JsonFormat(fromYAML), JsonFormat(fromJSON))
+	}
+	if time.Duration(fromYAML.Endpoint.Timeout) != 5*time.Second || time.Duration(fromYAML.Retry.Backoff) != 500*time.Millisecond {
+		t.Errorf("unexpected durations %+v", fromYAML)
+	}
+	if err := fromYAML.Validate(); err != nil {
+		t.Error(err)
+	}
+	network := fromYAML.Network.Network()
+	if network.Name != "testnet" || network.BaseChainId != 100007 || !network.HasChain(2) || network.HasChain(3) ||
+		network.SystemContracts != util.DefaultSystemContracts {
+		t.Errorf("unexpected network %+v", network)
+	}
+
+	for _, invalid := range []string{
+		"endpoint:\n\taddress: x",
+		"endpoint:\n  address: x\n    timeout: 1s",
+		"endpoint: |\n  x",
+		"endpoint:\n  address: x\n  unknown: 1",
+	} {
+		if _, err := web3.ParseYAMLConfig([]byte(invalid)); err == nil {
+			t.Errorf("invalid config is parsed: %q", invalid)
+		}
+	}
+	for _, invalid := range []string{
+		`{}`,
+		`{"endpoint": {"address": "x"}, "chainId": "1.5"}`,
+		`{"chains": {"main": {"address": "x"}}}`,
+		`{"endpoint": {"address": "x"}, "signer": {"passwordEnv": "KEY_PASSWORD"}}`,
+		`{"endpoint": {"address": "x"}, "network": {"systemContracts": {"withdraw": "0x02"}}}`,
+	} {
+		config, err := web3.ParseJSONConfig([]byte(invalid))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := config.Validate(); err == nil {
+			t.Errorf("invalid config is validated: %s", invalid)
+		}
+	}
+}
+
+func setEnv(t *testing.T, env map[string]string) func() {
+	for name, value := range env {
+		if err := os.Setenv(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return func() {
+		for name := range env {
+			_ = os.Unsetenv(name)
+		}
+	}
+}
+
+func TestConfigEnv(t *testing.T) {
+	defer setEnv(t, map[string]string{
+		"THK_ENDPOINT":         "127.0.0.1:8088",
+		"THK_CHAIN_ID":         "2",
+		"THK_CHAIN_3_ENDPOINT": "http://127.0.0.1:8090",
+		"THK_RETRY_MAX":        "5",
+		"THK_TIMEOUT":          "30s",
+	})()
+	config, err := web3.ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Endpoint.Address != "127.0.0.1:8088" || config.ChainId != "2" || config.Chains["3"].Address != "http://127.0.0.1:8090" ||
+		config.Retry.MaxRetries != 5 || time.Duration(config.Timeout) != 30*time.Second {
+		t.Errorf("unexpected config %s", JsonFormat(config))
+	}
+
+	defer setEnv(t, map[string]string{"THK_RETRY_MAX": "many"})()
+	if _, err := web3.ConfigFromEnv(); err == nil {
+		t.Error("invalid THK_RETRY_MAX is accepted")
+	}
+}
+
+func TestNewWeb3FromConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "thkconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	priv := "0x8e5b44b6cee8fa05092b4b5a8843aa6b0ec37915a940c9b5938e88a7e6fdd83a"
+	keyjson, err := keystore.EncryptKey(priv, "secret", 1<<4, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(dir, "key.json")
+	if err := ioutil.WriteFile(keyFile, keyjson, 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer setEnv(t, map[string]string{"THK_KEYSTORE_PASSWORD": "secret"})()
+
+	stats := func(height int) func(req rpcRequest) interface{} {
+		return func(req rpcRequest) interface{} {
+			return envelope(req, map[string]interface{}{"currentheight": height})
+		}
+	}
+	main, _ := newRPCServer(t, stats(1))
+	defer main.Close()
+	chain2, _ := newRPCServer(t, stats(2))
+	defer chain2.Close()
+
+	configFile := filepath.Join(dir, "config.yaml")
+	config := "endpoint:\n  address: " + main.URL + "\nchains:\n  2:\n    address: " + chain2.URL +
+		"\nchainId: 2\nsigner:\n  keystore: " + keyFile + "\nretry:\n  maxRetries: 1\n"
+	if err := ioutil.WriteFile(configFile, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := web3.LoadConfig(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := web3.NewWeb3FromConfig(loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Thk.DefaultChainId != "2" || client.Thk.DefaultPrivateKey != priv || client.Thk.DefaultAddress == "" {
+		t.Errorf("unexpected defaults %+v", client.Thk)
+	}
+	for chainId, height := range map[string]int{"1": 1, "2": 2, "3": 1} {
+		s, err := client.Thk.GetStats(chainId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.CurrentHeight != height {
+			t.Errorf("chain %s is sent to the endpoint of %d", chainId, s.CurrentHeight)
+		}
+	}
+
+	loaded.Signer.PasswordEnv = "THK_WRONG_PASSWORD"
+	if _, err := web3.NewWeb3FromConfig(loaded); err == nil {
+		t.Error("keystore is decrypted without password")
+	}
+}
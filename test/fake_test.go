@@ -0,0 +1,74 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// respond answers the nth request (from 1) of the fake provider by its params
+type respond func(n int, params interface{}) (interface{}, error)
+
+// fakeProvider answers each method by its result in results, or by result if the method has none.
+// A respond result is called for every request, and an error result fails the request. The
+// requests are recorded in order, and the max of concurrent requests is kept. Requests wait for
+// gate if it's not nil, and then for delay.
+type fakeProvider struct {
+	results map[string]interface{}
+	result  interface{}
+	gate    chan struct{}
+	delay   time.Duration
+
+	lock     sync.Mutex
+	methods  []string
+	requests []interface{}
+	inFlight int32
+	max      int32
+}
+
+func (p *fakeProvider) SendRequest(v interface{}, method string, params interface{}) error {
+	n := atomic.AddInt32(&p.inFlight, 1)
+	defer atomic.AddInt32(&p.inFlight, -1)
+	for {
+		max := atomic.LoadInt32(&p.max)
+		if n <= max || atomic.CompareAndSwapInt32(&p.max, max, n) {
+			break
+		}
+	}
+	p.lock.Lock()
+	p.methods = append(p.methods, method)
+	p.requests = append(p.requests, params)
+	count := len(p.methods)
+	result, ok := p.results[method]
+	if !ok {
+		result = p.result
+	}
+	p.lock.Unlock()
+	if p.gate != nil {
+		<-p.gate
+	}
+	time.Sleep(p.delay)
+	if f, ok := result.(respond); ok {
+		var err error
+		if result, err = f(count, params); err != nil {
+			return err
+		}
+	}
+	switch r := result.(type) {
+	case nil:
+		return fmt.Errorf("unexpected %s", method)
+	case error:
+		return r
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func (p *fakeProvider) Close() error {
+	return nil
+}
@@ -0,0 +1,96 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/ThinkiumGroup/web3.go/web3/keystore"
+	"testing"
+)
+
+// test vectors of the Web3 Secret Storage Definition, with the address of the key added
+const (
+	pbkdf2Keystore = `{
+	"address": "008aeeda4d805471df9b2a5b0f38a0c3bcba786b",
+	"crypto": {
+		"cipher": "aes-128-ctr",
+		"cipherparams": {"iv": "6087dab2f9fdbbfaddc31a909735c1e6"},
+		"ciphertext": "5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46",
+		"kdf": "pbkdf2",
+		"kdfparams": {
+			"c": 262144,
+			"dklen": 32,
+			"prf": "hmac-sha256",
+			"salt": "ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"
+		},
+		"mac": "517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"
+	},
+	"id": "3198bc9c-6672-5ab3-d995-4942343ae5b6",
+	"version": 3
+}`
+	scryptKeystore = `{
+	"address": "008aeeda4d805471df9b2a5b0f38a0c3bcba786b",
+	"crypto": {
+		"cipher": "aes-128-ctr",
+		"cipherparams": {"iv": "83dbcc02d8ccb40e466191a123791e0e"},
+		"ciphertext": "d172bf743a674da9cdad04534d56926ef8358534d458fffccd4e6ad2fbde479c",
+		"kdf": "scrypt",
+		"kdfparams": {
+			"dklen": 32,
+			"n": 262144,
+			"r": 1,
+			"p": 8,
+			"salt": "ab0c7876052600dd703518d6fc3fe8984592145b591fc8fb5c6d43190334ba19"
+		},
+		"mac": "2103ac29920d71da29f15d75b4a16dbe95cfd7ff8faea1056c33131d846e3097"
+	},
+	"id": "3198bc9c-6672-5ab3-d995-4942343ae5b6",
+	"version": 3
+}`
+	vectorPassword = "testpassword"
+	vectorKey      = "0x7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d"
+	vectorAddress  = "0x008aeeda4d805471df9b2a5b0f38a0c3bcba786b"
+)
+
+func TestKeystoreVectors(t *testing.T) {
+	for kdf, keyjson := range map[string]string{"pbkdf2": pbkdf2Keystore, "scrypt": scryptKeystore} {
+		key, err := keystore.DecryptKey([]byte(keyjson), vectorPassword)
+		if err != nil {
+			t.Errorf("%s: %v", kdf, err)
+			continue
+		}
+		if key.PrivateKey != vectorKey || key.Address != vectorAddress {
+			t.Errorf("%s: unexpected key %+v", kdf, key)
+		}
+		if _, err := keystore.DecryptKey([]byte(keyjson), "wrongpassword"); !errors.Is(err, keystore.ErrDecrypt) {
+			t.Errorf("%s: decrypted with wrong password: %v", kdf, err)
+		}
+	}
+}
+
+func TestKeystoreEncryptDecrypt(t *testing.T) {
+	priv := "0x8e5b44b6cee8fa05092b4b5a8843aa6b0ec37915a940c9b5938e88a7e6fdd83a"
+	keyjson, err := keystore.EncryptKey(priv, "foo", 1<<4, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := keystore.DecryptKey(keyjson, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.PrivateKey != priv || key.Address == "" {
+		t.Errorf("unexpected key %+v", key)
+	}
+	if _, err := keystore.DecryptKey(keyjson, "bar"); !errors.Is(err, keystore.ErrDecrypt) {
+		t.Errorf("decrypted with wrong password: %v", err)
+	}
+
+	var k map[string]interface{}
+	if err := json.Unmarshal(keyjson, &k); err != nil {
+		t.Fatal(err)
+	}
+	k["address"] = "0000000000000000000000000000000000000001"
+	other, _ := json.Marshal(k)
+	if _, err := keystore.DecryptKey(other, "foo"); err == nil {
+		t.Error("address of keystore is not checked")
+	}
+}
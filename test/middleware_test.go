@@ -0,0 +1,119 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/ThinkiumGroup/web3.go/web3/providers"
+	"github.com/ThinkiumGroup/web3.go/web3/thk"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMiddlewareHookOrder(t *testing.T) {
+	var order []string
+	hook := func(name string) providers.Hook {
+		return providers.HookFuncs{
+			BeforeFunc: func(call *providers.Call) { order = append(order, "before "+name) },
+			AfterFunc: func(call *providers.Call) {
+				order = append(order, "after "+name)
+				if call.Method != "GetStats" || call.ChainId != "3" || call.Duration <= 0 || call.ResponseSize == 0 {
+					t.Errorf("unexpected call %+v", call)
+				}
+			},
+		}
+	}
+	provider := providers.NewMiddlewareProvider(&fakeProvider{result: map[string]int{"currentheight": 9}, delay: 2 * time.Millisecond}, hook("a"), hook("b"))
+	stats, err := thk.NewThk(provider).GetStats("3")
+	if err != nil || stats.CurrentHeight != 9 {
+		t.Fatalf("unexpected stats %+v %v", stats, err)
+	}
+	if strings.Join(order, ",") != "before a,before b,after b,after a" {
+		t.Errorf("unexpected order %v", order)
+	}
+}
+
+func TestMetricsHook(t *testing.T) {
+	metrics := providers.NewMetrics(0.001, 1)
+	stub := &fakeProvider{result: map[string]int{"currentheight": 9}, delay: 2 * time.Millisecond}
+	mock := thk.NewThk(providers.NewMiddlewareProvider(stub, metrics))
+	for i := 0; i < 3; i++ {
+		_, _ = mock.GetStats("1")
+	}
+	_, _ = mock.GetStats("2")
+	stub.result = errors.New("connection refused")
+	_, _ = mock.GetStats("2")
+
+	all := metrics.Snapshot()
+	if len(all) != 2 || all[0].ChainId != "1" || all[0].Requests != 3 || all[1].Requests != 2 || all[1].Errors != 1 {
+		t.Fatalf("unexpected metrics %+v", all)
+	}
+	var buf bytes.Buffer
+	if err := metrics.WritePrometheus(&buf); err != nil {
+		t.Fatal(err)
+	}
+	text := buf.String()
+	for _, line := range []string{
+		`# TYPE thk_rpc_requests_total counter`,
+		`thk_rpc_requests_total{method="GetStats",chain="1"} 3`,
+		`thk_rpc_errors_total{method="GetStats",chain="2"} 1`,
+		`# TYPE thk_rpc_duration_seconds histogram`,
+		`thk_rpc_duration_seconds_bucket{method="GetStats",chain="1",le="1"} 3`,
+		`thk_rpc_duration_seconds_bucket{method="GetStats",chain="1",le="+Inf"} 3`,
+		`thk_rpc_duration_seconds_count{method="GetStats",chain="2"} 2`,
+	} {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("missing %q in\n%s", line, text)
+		}
+	}
+}
+
+func TestLogHookRedacts(t *testing.T) {
+	var buf bytes.Buffer
+	logHook := providers.NewLogHook(&buf)
+	logHook.LogParams = true
+	mock := thk.NewThk(providers.NewMiddlewareProvider(&fakeProvider{result: map[string]string{"TXhash": "0x01"}, delay: 2 * time.Millisecond}, logHook))
+	tx := &util.Transaction{ChainId: "1", From: "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23", Nonce: "1", Value: "1",
+		Sig: "0xsecretsig", Pub: "0x04pub", Multisigs: []string{"0xsecretmulti"}}
+	if _, err := mock.SendTx(tx); err != nil {
+		t.Fatal(err)
+	}
+	line := buf.String()
+	if strings.Contains(line, "secret") {
+		t.Errorf("signature is logged: %s", line)
+	}
+	var entry providers.LogEntry
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		t.Fatal(err)
+	}
+	params := entry.Params.(map[string]interface{})
+	if entry.Method != "SendTx" || entry.ChainId != "1" || params["sig"] != "[REDACTED]" || params["from"] != tx.From {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+}
+
+func TestTracingHook(t *testing.T) {
+	exporter := providers.NewInMemoryExporter()
+	stub := &fakeProvider{result: map[string]int{"currentheight": 9}, delay: 2 * time.Millisecond}
+	mock := thk.NewThk(providers.NewMiddlewareProvider(stub, providers.NewTracingHook(exporter)))
+	_, _ = mock.GetStats("1")
+	stub.result = errors.New("timeout")
+	_, _ = mock.GetStats("1")
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("expect 2 spans, but %d", len(spans))
+	}
+	if spans[0].Name != "GetStats" || spans[0].Status != providers.StatusOk || spans[0].Attributes["rpc.method"] != "GetStats" ||
+		spans[0].Attributes["thk.chain_id"] != "1" || !spans[0].EndTime.After(spans[0].StartTime) {
+		t.Errorf("unexpected span %+v", spans[0])
+	}
+	if spans[1].Status != providers.StatusError || spans[1].StatusDescription != "timeout" {
+		t.Errorf("unexpected span %+v", spans[1])
+	}
+	if spans[0].TraceID == spans[1].TraceID || spans[0].SpanID == spans[1].SpanID {
+		t.Error("spans are not unique")
+	}
+}
@@ -0,0 +1,189 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/ThinkiumGroup/web3.go/web3/providers"
+	"github.com/ThinkiumGroup/web3.go/web3/thk"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type rpcRequest struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// newRPCServer answers each request by respond, paths are recorded
+func newRPCServer(t *testing.T, respond func(req rpcRequest) interface{}) (*httptest.Server, *[]string) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		body, _ := ioutil.ReadAll(r.Body)
+		var res interface{}
+		if strings.HasPrefix(string(body), "[") {
+			var reqs []rpcRequest
+			if err := json.Unmarshal(body, &reqs); err != nil {
+				t.Error(err)
+			}
+			var batch []interface{}
+			// respond in reverse order
+			for i := len(reqs) - 1; i >= 0; i-- {
+				batch = append(batch, respond(reqs[i]))
+			}
+			res = batch
+		} else {
+			var req rpcRequest
+			if err := json.Unmarshal(body, &req); err != nil {
+				t.Error(err)
+			}
+			res = respond(req)
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	return server, &paths
+}
+
+func envelope(req rpcRequest, result interface{}) map[string]interface{} {
+	return map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result}
+}
+
+func newTestProvider(server *httptest.Server) *providers.HTTPProvider {
+	return providers.NewHTTPProvider(strings.TrimPrefix(server.URL, "http://"), 5, false)
+}
+
+func TestProviderEnvelope(t *testing.T) {
+	var ids []string
+	server, paths := newRPCServer(t, func(req rpcRequest) interface{} {
+		if req.Version != "2.0" || len(req.ID) == 0 {
+			t.Errorf("not a JSON-RPC 2.0 request: %+v", req)
+		}
+		ids = append(ids, string(req.ID))
+		if req.Method == "GetCommittee" {
+			return envelope(req, []string{"0x01"})
+		}
+		return envelope(req, map[string]interface{}{"chainId": 1, "currentheight": 100})
+	})
+	defer server.Close()
+	mock := thk.NewThk(newTestProvider(server))
+	for i := 0; i < 2; i++ {
+		stats, err := mock.GetStats("1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.CurrentHeight != 100 {
+			t.Errorf("unexpected stats %+v", stats)
+		}
+	}
+	if len(ids) != 2 || ids[0] == ids[1] {
+		t.Errorf("request ids %v are not unique", ids)
+	}
+	if _, err := mock.GetCommittee("1", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if (*paths)[2] != "/chaininfo" {
+		t.Errorf("request sent to %s", (*paths)[2])
+	}
+}
+
+func TestProviderLegacyResponse(t *testing.T) {
+	server, _ := newRPCServer(t, func(req rpcRequest) interface{} {
+		return map[string]interface{}{"chainId": 1, "currentheight": 100}
+	})
+	defer server.Close()
+	stats, err := thk.NewThk(newTestProvider(server)).GetStats("1")
+	if err != nil || stats.CurrentHeight != 100 {
+		t.Errorf("unexpected stats %+v %v", stats, err)
+	}
+}
+
+func TestProviderErrors(t *testing.T) {
+	server, _ := newRPCServer(t, func(req rpcRequest) interface{} {
+		switch req.Method {
+		case "GetStats":
+			return map[string]interface{}{"jsonrpc": "2.0", "id": 0, "result": map[string]interface{}{}}
+		default:
+			return map[string]interface{}{"jsonrpc": "2.0", "id": req.ID,
+				"error": map[string]interface{}{"code": -32000, "message": "chain not found"}}
+		}
+	})
+	defer server.Close()
+	mock := thk.NewThk(newTestProvider(server))
+	if _, err := mock.GetStats("1"); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("mismatched id is not detected: %v", err)
+	}
+	_, err := mock.GetAccount("0x2c7536e3605d9c16a7a3d7b1898e529396a65c23", "9")
+	var nodeErr *thk.NodeError
+	if !errors.As(err, &nodeErr) || !errors.Is(err, thk.ErrChainNotFound) || nodeErr.Code != -32000 {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestProviderBatch(t *testing.T) {
+	server, _ := newRPCServer(t, func(req rpcRequest) interface{} {
+		var params map[string]string
+		_ = json.Unmarshal(req.Params, &params)
+		if params["chainId"] == "9" {
+			return map[string]interface{}{"jsonrpc": "2.0", "id": req.ID,
+				"error": map[string]interface{}{"code": -32000, "message": "chain not found"}}
+		}
+		return envelope(req, params["chainId"])
+	})
+	defer server.Close()
+	var batch []*providers.BatchElem
+	results := make([]string, 3)
+	for i, chainId := range []string{"1", "2", "9"} {
+		batch = append(batch, &providers.BatchElem{Method: "GetStats",
+			Params: map[string]string{"chainId": chainId}, Result: &results[i]})
+	}
+	if err := newTestProvider(server).SendBatch(batch); err != nil {
+		t.Fatal(err)
+	}
+	if results[0] != "1" || results[1] != "2" || batch[0].Error != nil || batch[1].Error != nil {
+		t.Errorf("unexpected results %v", results)
+	}
+	var rpcErr *providers.RPCError
+	if !errors.As(batch[2].Error, &rpcErr) || rpcErr.Code != -32000 {
+		t.Errorf("unexpected error %v", batch[2].Error)
+	}
+}
+
+func TestRetryProvider(t *testing.T) {
+	counting := &fakeProvider{delay: 5 * time.Millisecond, result: respond(func(n int, params interface{}) (interface{}, error) {
+		switch n {
+		case 1:
+			return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+		case 2:
+			return nil, &providers.HTTPError{StatusCode: 502, Body: "bad gateway"}
+		case 3:
+			return map[string]string{"result": "ok"}, nil
+		default:
+			return nil, &providers.RPCError{Code: -32000, Message: "nonce too low"}
+		}
+	})}
+	provider := providers.NewRetryProvider(counting, providers.RetryOptions{MaxRetries: 2, Backoff: time.Millisecond})
+	var res map[string]string
+	if err := provider.SendRequest(&res, "GetStats", nil); err != nil || res["result"] != "ok" {
+		t.Fatalf("unexpected result %v %v", res, err)
+	}
+	// errors of the node are not retried
+	if err := provider.SendRequest(&res, "GetAccount", nil); err == nil || len(counting.methods) != 4 {
+		t.Errorf("unexpected error %v after %d requests", err, len(counting.methods))
+	}
+
+	// the transaction may be accepted before the connection is lost
+	counting = &fakeProvider{delay: 5 * time.Millisecond, result: respond(func(n int, params interface{}) (interface{}, error) {
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+	})}
+	provider = providers.NewRetryProvider(counting, providers.RetryOptions{MaxRetries: 2, Backoff: time.Millisecond})
+	if err := provider.SendRequest(&res, "SendTx", nil); err == nil || len(counting.methods) != 1 {
+		t.Errorf("SendTx is retried: %v after %d requests", err, len(counting.methods))
+	}
+}
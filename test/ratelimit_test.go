@@ -0,0 +1,118 @@
+package test
+
+import (
+	"github.com/ThinkiumGroup/web3.go/web3/providers"
+	"sync"
+	"testing"
+	"time"
+)
+
+func sendConcurrently(provider providers.ProviderInterface, methods ...string) {
+	var wg sync.WaitGroup
+	for _, method := range methods {
+		wg.Add(1)
+		go func(method string) {
+			defer wg.Done()
+			var res map[string]string
+			_ = provider.SendRequest(&res, method, nil)
+		}(method)
+	}
+	wg.Wait()
+}
+
+func repeat(method string, n int) []string {
+	methods := make([]string, n)
+	for i := range methods {
+		methods[i] = method
+	}
+	return methods
+}
+
+func TestRateLimitEndpoint(t *testing.T) {
+	provider := providers.NewRateLimitedProvider(&fakeProvider{delay: 5 * time.Millisecond, result: struct{}{}}, providers.RateLimitOptions{
+		Endpoint: providers.RateLimit{Rate: 100, Burst: 1},
+	})
+	start := time.Now()
+	sendConcurrently(provider, repeat("GetAccount", 11)...)
+	// 10 requests wait for tokens at 100/s
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("11 requests at 100/s finished in %s", elapsed)
+	}
+}
+
+func TestRateLimitMethod(t *testing.T) {
+	counting := &fakeProvider{delay: 5 * time.Millisecond, result: struct{}{}}
+	provider := providers.NewRateLimitedProvider(counting, providers.RateLimitOptions{
+		Methods: map[string]providers.RateLimit{"GetAccount": {Rate: 20, Burst: 1}},
+	})
+	start := time.Now()
+	go sendConcurrently(provider, repeat("GetAccount", 4)...)
+	time.Sleep(10 * time.Millisecond)
+	// GetStats is not blocked by the limit of GetAccount
+	sendConcurrently(provider, "GetStats")
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("GetStats is blocked for %s", elapsed)
+	}
+}
+
+func TestRateLimitInFlight(t *testing.T) {
+	counting := &fakeProvider{delay: 5 * time.Millisecond, result: struct{}{}}
+	provider := providers.NewRateLimitedProvider(counting, providers.RateLimitOptions{MaxInFlight: 2})
+	sendConcurrently(provider, repeat("GetAccount", 10)...)
+	if counting.max != 2 {
+		t.Errorf("expect 2 requests in flight, but %d", counting.max)
+	}
+}
+
+func TestRateLimitPriority(t *testing.T) {
+	counting := &fakeProvider{gate: make(chan struct{}), result: struct{}{}}
+	provider := providers.NewRateLimitedProvider(counting, providers.RateLimitOptions{MaxInFlight: 1})
+	done := make(chan struct{})
+	go func() {
+		sendConcurrently(provider, repeat("GetAccount", 6)...)
+		close(done)
+	}()
+	for provider.Pending() < 5 {
+		time.Sleep(time.Millisecond)
+	}
+	go sendConcurrently(provider, "SendTx")
+	for provider.Pending() < 6 {
+		time.Sleep(time.Millisecond)
+	}
+	close(counting.gate)
+	<-done
+	for provider.Pending() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	counting.lock.Lock()
+	defer counting.lock.Unlock()
+	if len(counting.methods) < 2 || counting.methods[1] != "SendTx" {
+		t.Errorf("SendTx is not sent first: %v", counting.methods)
+	}
+}
+
+func TestRateLimitBackoff(t *testing.T) {
+	counting := &fakeProvider{delay: 5 * time.Millisecond, result: respond(func(n int, params interface{}) (interface{}, error) {
+		switch n {
+		case 1:
+			return nil, &providers.HTTPError{StatusCode: 429, Body: "slow down"}
+		case 2:
+			return map[string]string{"ErrMsg": "Too many requests"}, nil
+		default:
+			return map[string]string{"result": "ok"}, nil
+		}
+	})}
+	provider := providers.NewRateLimitedProvider(counting, providers.RateLimitOptions{MinBackoff: 20 * time.Millisecond})
+	start := time.Now()
+	var res map[string]string
+	if err := provider.SendRequest(&res, "GetAccount", nil); err != nil {
+		t.Fatal(err)
+	}
+	// backoff of 20ms and 40ms
+	if elapsed := time.Since(start); res["result"] != "ok" || elapsed < 60*time.Millisecond {
+		t.Errorf("unexpected result %v after %s", res, elapsed)
+	}
+	if len(counting.methods) != 3 {
+		t.Errorf("expect 3 requests, but %d", len(counting.methods))
+	}
+}
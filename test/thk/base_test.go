@@ -2,10 +2,13 @@ package test
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/ThinkiumGroup/go-common"
 	"github.com/ThinkiumGroup/web3.go/common/hexutil"
 	"github.com/ThinkiumGroup/web3.go/test"
+	"github.com/ThinkiumGroup/web3.go/web3/dto"
+	"github.com/ThinkiumGroup/web3.go/web3/thk"
 	"strconv"
 	"strings"
 	"testing"
@@ -39,6 +42,35 @@ func TestThkGetBlockHeader(t *testing.T) {
 	fmt.Printf("res:%+v", res)
 }
 
+func TestGetTypedBlockHeader(t *testing.T) {
+	header, err := test.Web3.Thk.GetTypedBlockHeader("1", "983918")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	t.Logf("header:%x", header.Hash().Bytes())
+}
+
+func TestBlockHeaderMismatch(t *testing.T) {
+	header := &dto.BlockHeader{ChainID: 1, Height: 30, StateRoot: common.BytesToHash([]byte{1})}
+	hash, err := header.HashValue()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	summary := &dto.GetBlockResult{ChainId: 1, Height: 30, Hash: hexutil.Encode(hash), Stateroot: hexutil.Encode(header.StateRoot[:])}
+	if err = summary.Verify(header); err != nil {
+		t.Error(err)
+	}
+
+	summary.Hash = "0x6bd6a3d1068a3b748edc7ef70aee98749e33ddc3e03e10ca49dc4ca5fad4237c"
+	err = summary.Verify(header)
+	var mismatch *dto.HeaderMismatchError
+	if !errors.As(err, &mismatch) || mismatch.Field != "hash" || !errors.Is(err, dto.ErrHeaderMismatch) {
+		t.Errorf("forged hash should mismatch, but: %v", err)
+	}
+}
+
 func TestThkGetBlock(t *testing.T) {
 	res, err := test.Web3.Thk.GetBlock("1", "983918")
 	if err != nil {
@@ -83,6 +115,84 @@ func TestGetTxProof(t *testing.T) {
 	fmt.Printf("res:%+v", test.JsonFormat(res))
 }
 
+func TestVerifyTxProof(t *testing.T) {
+	hash := "0x22a38d12a1a12fe70573e3ec2ff0f5c9670dd7616883c38ec5f79adbca3da10a"
+	res, err := test.Web3.Thk.VerifyTxProof("2", hash, true)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	t.Logf("tx %x proved by %s of block %x, signatures: %d", res.TxHash[:], res.ProvedRoot, res.BlockHash[:], res.Signatures)
+}
+
+func TestVerifyTxInclusion(t *testing.T) {
+	txHash := hexutil.MustDecode("0x22a38d12a1a12fe70573e3ec2ff0f5c9670dd7616883c38ec5f79adbca3da10a")
+	proof := &dto.TxProof{
+		TxReceipt: dto.TxReceipt{Height: 10},
+		Proof: dto.MerkleItems{
+			{HashVal: hexutil.MustDecode("0x0101010101010101010101010101010101010101010101010101010101010101"), Direction: 0},
+			{HashVal: hexutil.MustDecode("0x0202020202020202020202020202020202020202020202020202020202020202"), Direction: 1},
+		},
+	}
+	root, err := proof.Proof.Proof(txHash)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	rootHash := common.BytesToHash(root)
+	header := &dto.BlockHeader{ChainID: 2, Height: 10, TransactionRoot: &rootHash}
+	res, err := thk.VerifyTxInclusion(txHash, proof, header)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if res.ProvedRoot != "TransactionRoot" {
+		t.Errorf("proved by %s", res.ProvedRoot)
+	}
+
+	proof.Proof[0].Direction = 1
+	if _, err = thk.VerifyTxInclusion(txHash, proof, header); !errors.Is(err, thk.ErrProofMismatch) {
+		t.Errorf("tampered proof should mismatch, but: %v", err)
+	}
+}
+
+func TestVerifiedAccount(t *testing.T) {
+	block, err := test.Web3.Thk.GetBlock("1", "983918")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	proof, err := test.Web3.Thk.VerifiedAccount(test.Web3.Thk.DefaultAddress, block.BlockHeader)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	t.Logf("balance:%s nonce:%d", proof.Account.Balance, proof.Account.Nonce)
+}
+
+func TestVerifyStorageProofSwappedKey(t *testing.T) {
+	block, err := test.Web3.Thk.GetBlock("1", "983918")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	slot0 := "0x0000000000000000000000000000000000000000000000000000000000000000"
+	slot1 := "0x0000000000000000000000000000000000000000000000000000000000000001"
+	proof, err := test.Web3.Thk.VerifiedAccount(erc20Address, block.BlockHeader, slot0, slot1)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if len(proof.Storage) != 2 {
+		t.Fatalf("%d storage proofs are returned", len(proof.Storage))
+	}
+	// the value and proof of each slot is claimed to be the other one's
+	proof.Storage[0].Key, proof.Storage[1].Key = proof.Storage[1].Key, proof.Storage[0].Key
+	if err = thk.VerifyAccountProof(proof, block.BlockHeader); !errors.Is(err, thk.ErrProofMismatch) {
+		t.Errorf("swapped storage keys should be rejected, but %v", err)
+	}
+}
+
 func TestThkPing(t *testing.T) {
 	res, err := test.Web3.Thk.Ping("192.168.1.14:23024")
 	if err != nil {
@@ -1,10 +1,18 @@
 package test
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"github.com/ThinkiumGroup/web3.go/common"
+	"github.com/ThinkiumGroup/web3.go/common/hexutil"
 	"github.com/ThinkiumGroup/web3.go/test"
 	"github.com/ThinkiumGroup/web3.go/web3/thk"
 	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
@@ -42,19 +50,158 @@ func TestEncodeDecode(t *testing.T) {
 	}
 }
 
+func TestDecodeChequeInput(t *testing.T) {
+	cases := []struct {
+		input string
+		typ   thk.ChequeInputType
+		nonce uint64
+	}{
+		{"0x00000001700fe44d941225d58e695c449f79412cc7fdbcf8000000000000000500000002700fe44d941225d58e695c449f79412cc7fdbcf80000000000cd35942000000000000000000000000000000000000000000000080900a88ac615440000", thk.ChequeInputCheque, 5},
+		{"0x9500000001700fe44d941225d58e695c449f79412cc7fdbcf8000000000000000800000002700fe44d941225d58e695c449f79412cc7fdbcf80000000000cd4ab00a0808bb44f943d050000000a40115c15bc00a6ecc6c41df074d3b206b4c92bf0135eaa1ad2e1bc5cb8ea19b5c0d90b5faf297941093a1b1de98b98e55dcd716559a4637f8d1093d3d2094acfb23a22d4a6beed4b8fc01c200008080940b934080c2ad4b80810004873886e8ed6d1fb4cfb76ee3a51743017fb843d4d75aebd1c7ff74af54a11efe6fe278546f23095d9045730bf9754685dc95a408c4c7187b75dd5c56ae2e4e40287dd0ee81fb16613e9c19f51b9ca42dd34bc08339a27817d402f7959b13f5235308633a3ff8f0cb4de954288da3be5873100efe659c25ef05cd6bc0653e7257000107940e934080c2ffff80810004511655f6352ed0decc7276b684df10bb46feff9a908acf0df2bd6016a17ec973a03f7a59bd2949c4f5d4a38522e7ca2a3c2f4734cbf3303a6cf40f5d037a25016ec88920b0aed933b26975be441f071e7bd8f93e78d5ca1430177b67d7d5fb0c6d0e347c5679245f580a4b2da483c3019e575111b6ce65978fa59d220db2548000010e9404934080c2ffff80810004844fba978763858c37126d7c256598ea19a781b6ed81af5bd152c40d406f60fff11def743b04694881d7493ad4c3cb448f00ed0ab90e11d6a587fd99f0dc0e4986adab2d712eefb87c4c5c462a36efa0a8ea8e33103f7a0d21e7998fc582c00dce7e3cc6f7f75806eff5c6a5cc687e9c04f90079ae5b34b65a4b5cbf6f0070c40001049424930080c20000c089e7efb17aeab2d0c342d07f124096cb88aa39b6e634d560e4ae49d5cab5ee53810005423ba7e430ec3184e82c8df70aa2c5df8af08a01dae25e1331089cb7c3a29ec508119a9021bd4e60a1630ed667f901dccc3464f02ff1f203200b87d0152b1c915c3e0f832b46bcce0f17b37c08c1a268808ff789fae75d6709a702c7e1727a66d9c741a52af4683582993cf7839c8597457a0befe67682bee502d1f21e49db22db14fadeae9557e9abb544add89d2a2d22c35591f04b178f18e253f827cc03f80001109411930080c20000c012440ed40e7975972d7b55218afd52f6f7208d7cd9cd87f498717c3d77c3e5ec810002b95a5048621c6adff0e6e0501ed5f83ee6a8c195c21914dc3cff8e9ceb79b27d8f1cf6230f0498dc26f33ddb993633646822c8da316cf4574bdcb2f86872990c00009426930080c20000c0fa4cd1b148975f4b52aeb6a82f63ed2e90f9ede7c138dab58b1a135f578a959e810005b2cbeb6508244f5c9a8a0ee618068ef410d904d42bd652b6cf25576c58b04e89e2c0da358e91eed86a0658f202bb8fb2050f9e82cf7e9ba51b02bf35a7f3195f731728437fd64a11e98169b11432e9c9ebf78ca70978206e19a72ad539d82180d98df14cbc3939a40effc8c9b294d8af3733caa3370b2adc34f74c6730fd54a0590f25f3e5de0665d4004ee5514a7ab97587284f8d7ec07755a722990fba7bc9000114", thk.ChequeInputCash, 8},
+		{"0x9600000001700fe44d941225d58e695c449f79412cc7fdbcf8000000000000000500000002700fe44d941225d58e695c449f79412cc7fdbcf80000000000cd35940a080900a88ac61544000001a3cd49bdc01ed94d694f64b56225597e0cafe84a5efa709668ec8a3e4263095ff77052a7929394a1fe934080c2808280810001a09b033a1b8e6c386cf40180fafd9b9b335d9bb2ff665fa839b8de677788daac00009402934080c2ffff808100042364b4ed58f756968196789b56db2c3b1bd17b8b6044e551509d192b41db94404e7362b575d64f6c427b95366f603c9ebccbdd7bc71794fbca0c397a86836109f5bb809bb7812adf54cda134eb20eeb52224114087820bf35460afb959f3d8fc17346d5b3b64c12a0dd1bf330b2b44f9e58b9fe8350b61b70bca9f4fc96f63b2000102940a934080c2ffff80810004389db1c4fcf2cb709480a8b7ad196550c3dd7d1cc80b81057c293157f784a23fd91f1c67631704b1e5048919c2de81dc7141da9ac69bf4a4a500d71f9042e9d3d8b99f2e75c80afef28f9384917c983cc08c9c53ecdff238a309acfd6220538291017b8ffabe46fe47d45fa88aba0e238ffa40ed61cc84f06a0ee5eb285dacb300010a919425930080c20000c0c5e5be3bc5e6df278b0a01e44cdded05cfe69ee1c592e662dc6bad7ac08e4bd3810005d07655ca81f8d75de2a376801feedcf348f5ff21a1c04c45eb3b146c11456b5413d5086b86bf654eb34ab7317fe0b70109e55f6376999cad571b927be0d507222f8e331e16e453e0da085eadb2705e556b4bb4693835b3f22c1c17f1dcfccc425998e44e70d863daea2d602751182a878eaa7c6e4449325e14d92f13287df5dfea4079ecb21ff0770dc9febc35bc47160ac167362f51a0ac27edcd156d4b3da8000111", thk.ChequeInputCancel, 5},
+	}
+	for _, c := range cases {
+		typ, check, err := thk.DecodeChequeInput(c.input)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if typ != c.typ || check.Nonce != c.nonce || check.FromChain != 1 || check.ToChain != 2 {
+			t.Errorf("decode %s input failed: %s, %s", c.typ, typ, check)
+		}
+	}
+}
+
+const chequeBody = "000000012c7536e3605d9c16a7a3d7b1898e529396a65c230000000000000005000000024fa1c4e6182b6b7f3bca273390cf587b50b473110000000000000064080de0b6b3a7640000"
+
+func newTestCashCheck() thk.CashCheck {
+	return thk.CashCheck{
+		FromChain:    1,
+		FromAddress:  common.HexToAddress("0x2c7536e3605d9c16a7a3d7b1898e529396a65c23"),
+		Nonce:        5,
+		ToChain:      2,
+		ToAddress:    common.HexToAddress("0x4fa1c4e6182b6b7f3bca273390cf587b50b47311"),
+		ExpireHeight: 100,
+		Amount:       new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil),
+	}
+}
+
+func TestCashCheckPrefix(t *testing.T) {
+	cases := []struct {
+		name        string
+		parentChain common.ChainId
+		isShard     bool
+		userLocal   bool
+		currencyID  common.ChainId
+		prefix      string
+	}{
+		{"v0", 0, false, false, 0, ""},
+		{"v1 local currency", 0, false, true, 3, "00100000010100000000000003"},
+		{"v1 currency", 0, false, false, 3, "00100000010000000000000003"},
+		{"v1 parent chain", 7, false, false, 0, "00100000010000000007000000"},
+		{"v1 shard", 7, true, false, 0, "00100000010000000007010000"},
+		{"v1 all", 7, true, true, 3, "00100000010100000007010003"},
+	}
+	for _, c := range cases {
+		check := newTestCashCheck()
+		check.ParentChain, check.IsShard, check.UserLocal, check.CurrencyID = c.parentChain, c.isShard, c.userLocal, c.currencyID
+		buf := new(bytes.Buffer)
+		if err := check.Serialization(buf); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if got := hexutil.Encode(buf.Bytes()); got != "0x"+c.prefix+chequeBody {
+			t.Errorf("%s: encoded %s", c.name, got)
+		}
+		var decoded thk.CashCheck
+		if _, err := decoded.Deserialization(bytes.NewReader(buf.Bytes())); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if decoded.String() != check.String() {
+			t.Errorf("%s: decoded %s, want %s", c.name, decoded.String(), check.String())
+		}
+	}
+}
+
+func TestCashCheckLegacyPrefix(t *testing.T) {
+	// version 0x0 header only marks UserLocal
+	var decoded thk.CashCheck
+	if _, err := decoded.Deserialization(bytes.NewReader(hexutil.MustDecode("0x0010000000" + chequeBody))); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if !decoded.UserLocal || decoded.ParentChain != 0 || decoded.IsShard || decoded.CurrencyID != 0 || decoded.Nonce != 5 {
+		t.Errorf("decoded %s", decoded.String())
+	}
+
+	if _, err := decoded.Deserialization(bytes.NewReader(hexutil.MustDecode("0x0010000002" + chequeBody))); err == nil {
+		t.Error("unknown version should fail")
+	}
+}
+
+func TestCashCheckInvalid(t *testing.T) {
+	check := newTestCashCheck()
+	check.UserLocal = true
+	if err := check.Serialization(new(bytes.Buffer)); err == nil {
+		t.Error("UserLocal without CurrencyID should fail")
+	}
+	check.CurrencyID = 0x10000
+	if err := check.Serialization(new(bytes.Buffer)); err == nil {
+		t.Error("CurrencyID out of range should fail")
+	}
+}
+
+func TestCashChequeRoundTrip(t *testing.T) {
+	cheques := []thk.CashCheque{
+		{ChainId: "1", FromChainId: "1", From: "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23", Nonce: "5", ToChainId: "2",
+			To: "0x4fa1c4e6182b6b7f3bca273390cf587b50b47311", ExpireHeight: "100", Value: "1000000000000000000"},
+		{ChainId: "1", FromChainId: "1", From: "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23", Nonce: "5", ToChainId: "2",
+			To: "0x4fa1c4e6182b6b7f3bca273390cf587b50b47311", ExpireHeight: "100", Value: "1000000000000000000",
+			ParentChain: "7", IsShard: true, UserLocal: true, CurrencyId: "3"},
+	}
+	for _, cheque := range cheques {
+		input, err := cheque.Encode()
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		var decoded thk.CashCheque
+		if err = decoded.Decode(input); err != nil {
+			t.Error(err)
+			continue
+		}
+		if decoded != cheque {
+			t.Errorf("round trip failed: %+v, want %+v", decoded, cheque)
+		}
+	}
+
+	invalid := cheques[0]
+	invalid.UserLocal = true
+	if _, err := invalid.Encode(); err == nil {
+		t.Error("UserLocal without CurrencyId should fail")
+	}
+}
+
 func TestTransferAcrossChain(t *testing.T) {
 	expireAfter = 200
 	fmt.Println("===Write a check===")
 	cheque := genCheque(t)
 	fmt.Println("===Get proof of cashing a check===")
-	proof := getChequeProof(cheque, t)
+	time.Sleep(5 * time.Second)
+	proof, err := test.Web3.Thk.RpcMakeVccProof(cheque)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
 
 	fmt.Println("===Cash a check===")
-	tx := util.Transaction{
-		ChainId: toChainId, FromChainId: toChainId, ToChainId: toChainId, From: test.Web3.Thk.DefaultAddress,
-		To: thk.SystemContractAddressDeposit, Value: "0", Input: proof,
+	tx, err := proof.DepositTransaction(test.Web3.Thk.DefaultAddress, test.Web3.Thk.Network.SystemContracts)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
 	}
-	cashOrCancelCheque(tx, t)
+	cashOrCancelCheque(*tx, t)
 }
 
 func TestCancelCheque(t *testing.T) {
@@ -88,6 +235,238 @@ func TestCancelCheque(t *testing.T) {
 	cashOrCancelCheque(tx2, t)
 }
 
+func TestCrossChainTransfer(t *testing.T) {
+	chainInfo, err := test.Web3.Thk.GetStats(toChainId)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	nonce, err := test.Web3.Thk.GetNonce(test.Web3.Thk.DefaultAddress, fromChainId)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	cheque := &thk.CashCheque{
+		ChainId:      fromChainId,
+		FromChainId:  fromChainId,
+		From:         test.Web3.Thk.DefaultAddress,
+		Nonce:        strconv.Itoa(int(nonce)),
+		ToChainId:    toChainId,
+		To:           test.Web3.Thk.DefaultAddress,
+		ExpireHeight: strconv.Itoa(chainInfo.CurrentHeight + 200),
+		Value:        chequeValue,
+	}
+	dir, err := ioutil.TempDir("", "transfer")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	store := thk.FileTransferStore(filepath.Join(dir, "transfer.json"))
+
+	transfer := test.Web3.Thk.NewCrossChainTransfer(cheque, test.Web3.Thk.DefaultPrivateKey, store)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	if err = transfer.Run(ctx); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	state, err := store.Load()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	fmt.Println(test.JsonFormat(state))
+	if state.Step != thk.TransferCashed {
+		t.Errorf("transfer should be cashed, but %s", state.Step)
+	}
+}
+
+// recordStore records the states saved by a transfer
+type recordStore []thk.TransferState
+
+func (s *recordStore) Save(state *thk.TransferState) error {
+	*s = append(*s, *state)
+	return nil
+}
+
+func TestCrossChainTransferWithdraw(t *testing.T) {
+	key := "0x8e5b44b6cee8fa05092b4b5a8843aa6b0ec37915a940c9b5938e88a7e6fdd83a"
+	from := "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23"
+	cheque := &thk.CashCheque{ChainId: "1", FromChainId: "1", From: from, Nonce: "7", ToChainId: "2", To: from,
+		ExpireHeight: "1200", Value: chequeValue}
+	input, err := cheque.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	withdraw := map[string]interface{}{"from": from, "to": thk.SystemContractAddressWithdraw, "nonce": 7,
+		"input": input, "hash": "0x0a"}
+	other := map[string]interface{}{"from": from, "to": from, "nonce": 7, "input": "0x", "hash": "0x0b"}
+	step := func(nonce int, withdrawHeight string, txs ...interface{}) (*thk.CrossChainTransfer, *fakeProvider, recordStore) {
+		provider := &fakeProvider{results: map[string]interface{}{
+			"GetAccount":      map[string]interface{}{"address": from, "nonce": nonce},
+			"GetStats":        map[string]interface{}{"currentheight": 1000},
+			"GetTransactions": txs,
+			"SendTx":          map[string]string{"TXhash": "0x0c"},
+		}}
+		var store recordStore
+		transfer := thk.NewThk(provider).ResumeCrossChainTransfer(
+			&thk.TransferState{Step: thk.TransferNew, Cheque: cheque, WithdrawHeight: withdrawHeight}, key, &store)
+		// the error of a failed transfer is checked by its state
+		transfer.Step()
+		return transfer, provider, store
+	}
+
+	// the height is saved before the withdraw is sent
+	transfer, provider, store := step(7, "")
+	if len(store) != 2 || store[0].Step != thk.TransferNew || store[0].WithdrawHeight != "1000" {
+		t.Fatalf("states before the withdraw is sent: %+v", store)
+	}
+	if sent := provider.sent(); len(sent) != 1 || sent[0].Nonce != "7" || sent[0].Input != input {
+		t.Fatalf("withdraw transactions: %+v", sent)
+	}
+	if transfer.State.Step != thk.TransferWithdrawSent || transfer.State.WithdrawHash != "0x0c" {
+		t.Errorf("state after the withdraw is sent: %+v", transfer.State)
+	}
+
+	// resumed after the withdraw is sent
+	previous := map[string]interface{}{"from": from, "to": from, "nonce": 6, "input": "0x", "hash": "0x09"}
+	transfer, provider, _ = step(8, "990", previous, withdraw)
+	if transfer.State.Step != thk.TransferWithdrawSent || transfer.State.WithdrawHash != "0x0a" {
+		t.Errorf("state of the resumed withdraw: %+v", transfer.State)
+	}
+	if len(provider.sent()) != 0 {
+		t.Error("withdraw is sent again")
+	}
+
+	// the nonce is used by another transaction
+	for _, transfer := range []*thk.CrossChainTransfer{
+		func() *thk.CrossChainTransfer { transfer, _, _ := step(8, "", withdraw); return transfer }(),
+		func() *thk.CrossChainTransfer { transfer, _, _ := step(8, "990", other); return transfer }(),
+	} {
+		if transfer.State.Step != thk.TransferFailed || transfer.State.WithdrawHash != "" {
+			t.Errorf("transfer of a used nonce: %+v", transfer.State)
+		}
+	}
+}
+
+func TestCrossChainTransferResume(t *testing.T) {
+	key := "0x8e5b44b6cee8fa05092b4b5a8843aa6b0ec37915a940c9b5938e88a7e6fdd83a"
+	// the cheque of the cash and cancel inputs, which are from chain 1 to chain 2
+	from := "0x700fe44d941225d58e695c449f79412cc7fdbcf8"
+	cashInput := "0x9500000001700fe44d941225d58e695c449f79412cc7fdbcf8000000000000000800000002700fe44d941225d58e695c449f79412cc7fdbcf80000000000cd4ab00a0808bb44f943d050000000a40115c15bc00a6ecc6c41df074d3b206b4c92bf0135eaa1ad2e1bc5cb8ea19b5c0d90b5faf297941093a1b1de98b98e55dcd716559a4637f8d1093d3d2094acfb23a22d4a6beed4b8fc01c200008080940b934080c2ad4b80810004873886e8ed6d1fb4cfb76ee3a51743017fb843d4d75aebd1c7ff74af54a11efe6fe278546f23095d9045730bf9754685dc95a408c4c7187b75dd5c56ae2e4e40287dd0ee81fb16613e9c19f51b9ca42dd34bc08339a27817d402f7959b13f5235308633a3ff8f0cb4de954288da3be5873100efe659c25ef05cd6bc0653e7257000107940e934080c2ffff80810004511655f6352ed0decc7276b684df10bb46feff9a908acf0df2bd6016a17ec973a03f7a59bd2949c4f5d4a38522e7ca2a3c2f4734cbf3303a6cf40f5d037a25016ec88920b0aed933b26975be441f071e7bd8f93e78d5ca1430177b67d7d5fb0c6d0e347c5679245f580a4b2da483c3019e575111b6ce65978fa59d220db2548000010e9404934080c2ffff80810004844fba978763858c37126d7c256598ea19a781b6ed81af5bd152c40d406f60fff11def743b04694881d7493ad4c3cb448f00ed0ab90e11d6a587fd99f0dc0e4986adab2d712eefb87c4c5c462a36efa0a8ea8e33103f7a0d21e7998fc582c00dce7e3cc6f7f75806eff5c6a5cc687e9c04f90079ae5b34b65a4b5cbf6f0070c40001049424930080c20000c089e7efb17aeab2d0c342d07f124096cb88aa39b6e634d560e4ae49d5cab5ee53810005423ba7e430ec3184e82c8df70aa2c5df8af08a01dae25e1331089cb7c3a29ec508119a9021bd4e60a1630ed667f901dccc3464f02ff1f203200b87d0152b1c915c3e0f832b46bcce0f17b37c08c1a268808ff789fae75d6709a702c7e1727a66d9c741a52af4683582993cf7839c8597457a0befe67682bee502d1f21e49db22db14fadeae9557e9abb544add89d2a2d22c35591f04b178f18e253f827cc03f80001109411930080c20000c012440ed40e7975972d7b55218afd52f6f7208d7cd9cd87f498717c3d77c3e5ec810002b95a5048621c6adff0e6e0501ed5f83ee6a8c195c21914dc3cff8e9ceb79b27d8f1cf6230f0498dc26f33ddb993633646822c8da316cf4574bdcb2f86872990c00009426930080c20000c0fa4cd1b148975f4b52aeb6a82f63ed2e90f9ede7c138dab58b1a135f578a959e810005b2cbeb6508244f5c9a8a0ee618068ef410d904d42bd652b6cf25576c58b04e89e2c0da358e91eed86a0658f202bb8fb2050f9e82cf7e9ba51b02bf35a7f3195f731728437fd64a11e98169b11432e9c9ebf78ca70978206e19a72ad539d82180d98df14cbc3939a40effc8c9b294d8af3733caa3370b2adc34f74c6730fd54a0590f25f3e5de0665d4004ee5514a7ab97587284f8d7ec07755a722990fba7bc9000114"
+	cancelInput := "0x9600000001700fe44d941225d58e695c449f79412cc7fdbcf8000000000000000500000002700fe44d941225d58e695c449f79412cc7fdbcf80000000000cd35940a080900a88ac61544000001a3cd49bdc01ed94d694f64b56225597e0cafe84a5efa709668ec8a3e4263095ff77052a7929394a1fe934080c2808280810001a09b033a1b8e6c386cf40180fafd9b9b335d9bb2ff665fa839b8de677788daac00009402934080c2ffff808100042364b4ed58f756968196789b56db2c3b1bd17b8b6044e551509d192b41db94404e7362b575d64f6c427b95366f603c9ebccbdd7bc71794fbca0c397a86836109f5bb809bb7812adf54cda134eb20eeb52224114087820bf35460afb959f3d8fc17346d5b3b64c12a0dd1bf330b2b44f9e58b9fe8350b61b70bca9f4fc96f63b2000102940a934080c2ffff80810004389db1c4fcf2cb709480a8b7ad196550c3dd7d1cc80b81057c293157f784a23fd91f1c67631704b1e5048919c2de81dc7141da9ac69bf4a4a500d71f9042e9d3d8b99f2e75c80afef28f9384917c983cc08c9c53ecdff238a309acfd6220538291017b8ffabe46fe47d45fa88aba0e238ffa40ed61cc84f06a0ee5eb285dacb300010a919425930080c20000c0c5e5be3bc5e6df278b0a01e44cdded05cfe69ee1c592e662dc6bad7ac08e4bd3810005d07655ca81f8d75de2a376801feedcf348f5ff21a1c04c45eb3b146c11456b5413d5086b86bf654eb34ab7317fe0b70109e55f6376999cad571b927be0d507222f8e331e16e453e0da085eadb2705e556b4bb4693835b3f22c1c17f1dcfccc425998e44e70d863daea2d602751182a878eaa7c6e4449325e14d92f13287df5dfea4079ecb21ff0770dc9febc35bc47160ac167362f51a0ac27edcd156d4b3da8000111"
+	step := func(state thk.TransferState, results map[string]interface{}) (*thk.CrossChainTransfer, *fakeProvider, recordStore) {
+		provider := &fakeProvider{results: map[string]interface{}{
+			"GetStats":   map[string]interface{}{"currentheight": 1000},
+			"GetAccount": map[string]interface{}{"address": from, "nonce": 3},
+			"SendTx":     map[string]string{"TXhash": "0x0c"},
+		}}
+		for method, result := range results {
+			provider.results[method] = result
+		}
+		state.Cheque = &thk.CashCheque{ChainId: "1", FromChainId: "1", From: from, Nonce: "5", ToChainId: "2", To: from,
+			ExpireHeight: "1200", Value: chequeValue}
+		var store recordStore
+		transfer := thk.NewThk(provider).ResumeCrossChainTransfer(&state, key, &store)
+		// the error of a failed transfer is checked by its state
+		transfer.Step()
+		return transfer, provider, store
+	}
+	tx := func(to string, nonce int, input, hash string) map[string]interface{} {
+		return map[string]interface{}{"from": from, "to": to, "nonce": nonce, "input": input, "hash": hash}
+	}
+
+	// the nonce and the height are saved before the deposit or the cancel is sent
+	for _, c := range []struct {
+		height  int
+		proof   map[string]interface{}
+		pending thk.TransferStep
+		next    thk.TransferStep
+		to      string
+	}{
+		{1000, map[string]interface{}{"RpcMakeVccProof": map[string]interface{}{"input": cashInput}},
+			thk.TransferDepositPending, thk.TransferDepositSent, thk.SystemContractAddressDeposit},
+		{1300, map[string]interface{}{"MakeCCCExistenceProof": map[string]interface{}{"input": cancelInput}},
+			thk.TransferCancelPending, thk.TransferCancelSent, thk.SystemContractAddressCancel},
+	} {
+		c.proof["GetStats"] = map[string]interface{}{"currentheight": c.height}
+		transfer, provider, store := step(thk.TransferState{Step: thk.TransferWithdrawn}, c.proof)
+		if len(store) != 2 || store[0].Step != c.pending || store[0].SendNonce != "3" || store[0].SendHeight != strconv.Itoa(c.height) {
+			t.Errorf("states before %s: %+v", c.next, store)
+		}
+		if sent := provider.sent(); len(sent) != 1 || sent[0].Nonce != "3" || sent[0].To != c.to {
+			t.Errorf("transactions of %s: %+v", c.next, sent)
+		}
+		if transfer.State.Step != c.next || transfer.State.DepositHash+transfer.State.CancelHash != "0x0c" {
+			t.Errorf("state after %s: %+v", c.next, transfer.State)
+		}
+	}
+
+	// resumed after the deposit or the cancel is sent, or before it's sent
+	for _, c := range []struct {
+		step  thk.TransferStep
+		nonce int
+		txs   []interface{}
+		want  thk.TransferStep
+		hash  string
+	}{
+		{thk.TransferDepositPending, 4, []interface{}{tx(thk.SystemContractAddressDeposit, 3, cashInput, "0x0a")}, thk.TransferDepositSent, "0x0a"},
+		{thk.TransferDepositPending, 3, nil, thk.TransferWithdrawn, ""},
+		{thk.TransferDepositPending, 4, []interface{}{tx(from, 3, "0x", "0x0b")}, thk.TransferWithdrawn, ""},
+		{thk.TransferCancelPending, 4, []interface{}{tx(thk.SystemContractAddressCancel, 3, cancelInput, "0x0a")}, thk.TransferCancelSent, "0x0a"},
+		{thk.TransferCancelPending, 3, nil, thk.TransferWithdrawn, ""},
+	} {
+		transfer, provider, _ := step(thk.TransferState{Step: c.step, SendNonce: "3", SendHeight: "990"}, map[string]interface{}{
+			"GetAccount":      map[string]interface{}{"address": from, "nonce": c.nonce},
+			"GetTransactions": c.txs,
+		})
+		if transfer.State.Step != c.want || transfer.State.DepositHash+transfer.State.CancelHash != c.hash {
+			t.Errorf("%s resumed at nonce %d: %+v", c.step, c.nonce, transfer.State)
+		}
+		if len(provider.sent()) != 0 {
+			t.Errorf("%s resumed at nonce %d: transaction is sent again", c.step, c.nonce)
+		}
+	}
+
+	// a deposit or a cancel fails if the cheque has been cashed or cancelled
+	failed := map[string]interface{}{"status": 0}
+	transfer, _, _ := step(thk.TransferState{Step: thk.TransferDepositSent, DepositHash: "0x0c"}, map[string]interface{}{
+		"GetTransactionByHash":  failed,
+		"MakeCCCExistenceProof": map[string]interface{}{"existence": true},
+	})
+	if transfer.State.Step != thk.TransferCashed {
+		t.Errorf("deposit of a cashed cheque: %+v", transfer.State)
+	}
+	transfer, _, _ = step(thk.TransferState{Step: thk.TransferDepositSent, DepositHash: "0x0c"}, map[string]interface{}{
+		"GetTransactionByHash":  failed,
+		"MakeCCCExistenceProof": map[string]interface{}{"existence": false, "input": cancelInput},
+	})
+	if transfer.State.Step != thk.TransferFailed {
+		t.Errorf("failed deposit: %+v", transfer.State)
+	}
+	status := map[string]int{"0x0a": 1}
+	for _, txs := range [][]interface{}{
+		{tx(from, 1, "0x", "0x09"), tx(thk.SystemContractAddressCancel, 2, cancelInput, "0x0a")},
+		{tx(thk.SystemContractAddressCancel, 2, cancelInput, "0x0b")},
+	} {
+		transfer, _, _ = step(thk.TransferState{Step: thk.TransferCancelSent, CancelHash: "0x0c", WithdrawHeight: "900"}, map[string]interface{}{
+			"GetStats":        map[string]interface{}{"currentheight": 1300},
+			"GetTransactions": txs,
+			"GetTransactionByHash": respond(func(n int, params interface{}) (interface{}, error) {
+				return map[string]interface{}{"status": status[params.(util.GetTxByHash).Hash]}, nil
+			}),
+		})
+		if hash := txs[len(txs)-1].(map[string]interface{})["hash"]; hash == "0x0a" {
+			if transfer.State.Step != thk.TransferCancelled || transfer.State.CancelHash != "0x0a" {
+				t.Errorf("cancel of a cancelled cheque: %+v", transfer.State)
+			}
+		} else if transfer.State.Step != thk.TransferFailed {
+			t.Errorf("cancel after a failed cancel: %+v", transfer.State)
+		}
+	}
+}
+
 func genCheque(t *testing.T) *thk.CashCheque {
 	chainInfo, err := test.Web3.Thk.GetStats(toChainId)
 	if err != nil {
@@ -156,11 +535,7 @@ func getChequeProof(cashCheque *thk.CashCheque, t *testing.T) string {
 		t.FailNow()
 	}
 	fmt.Printf("proofRes:%+v\n", proofRes)
-	if proofRes["errCode"] != nil {
-		t.Error("get vccProofInput error")
-		t.FailNow()
-	}
-	return proofRes["input"].(string)
+	return proofRes.Input
 }
 
 func getCancelChequeProof(cashCheque *thk.CashCheque, t *testing.T) string {
@@ -170,16 +545,12 @@ func getCancelChequeProof(cashCheque *thk.CashCheque, t *testing.T) string {
 		t.Error(err.Error())
 		t.FailNow()
 	}
-	if res["errCode"] != nil {
-		t.Error(res["errMsg"])
-		t.FailNow()
-	}
 	fmt.Printf("res:%v\n", res)
-	if res["existence"].(bool) == true {
+	if res.Existence {
 		t.Error("cheque has been cashed")
 		t.FailNow()
 	}
-	return res["input"].(string)
+	return res.Input
 }
 
 func cashOrCancelCheque(tx util.Transaction, t *testing.T) {
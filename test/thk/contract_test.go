@@ -0,0 +1,107 @@
+package test
+
+import (
+	"fmt"
+	"github.com/ThinkiumGroup/web3.go/web3/thk"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestContractAddress(t *testing.T) {
+	// contracts created by 0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0 on Ethereum, whose contract
+	// addresses are derived in the same way: the last 20 bytes of keccak256(rlp([from, nonce]))
+	from := "0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0"
+	for nonce, want := range []string{
+		"0xcd234a471b72ba2f1ccf0a70fcaba648a5eecd8d",
+		"0x343c43a37d37dff08ae8c4a11544c718abb4fcf8",
+		"0xf778b86fa74e846c4f0a1fbd1335fe81c00a0c91",
+		"0xfffd933a0bc612844eaf0c6fe3e5b8e9b6c1d19c",
+	} {
+		address, err := thk.ContractAddress(from, fmt.Sprint(nonce))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if address != want {
+			t.Errorf("nonce %d: contract address %s, want %s", nonce, address, want)
+		}
+	}
+}
+
+func TestDeployAndWaitAddress(t *testing.T) {
+	key := "0x8e5b44b6cee8fa05092b4b5a8843aa6b0ec37915a940c9b5938e88a7e6fdd83a"
+	from := "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23"
+	expected, err := thk.ContractAddress(from, "5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deploy := func(receiptAddress string) (*thk.DeployResult, error) {
+		provider := &fakeProvider{results: map[string]interface{}{
+			"SendTx":               map[string]string{"TXhash": "0x01"},
+			"GetTransactionByHash": map[string]interface{}{"status": 1, "contractAddress": receiptAddress},
+			"GetAccount":           map[string]interface{}{"codeHash": []byte{1}},
+		}}
+		contract, err := thk.NewThk(provider).NewContract("function f()")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx := util.Transaction{ChainId: "1", FromChainId: "1", ToChainId: "1", From: from, Value: "0", Nonce: "5"}
+		return contract.DeployAndWait(tx, "0x6080", key)
+	}
+
+	for _, address := range []string{expected, strings.ToUpper(expected[2:]), ""} {
+		if address != "" && !strings.HasPrefix(address, "0x") {
+			address = "0x" + address
+		}
+		result, err := deploy(address)
+		if err != nil {
+			t.Fatalf("receipt address %q: %v", address, err)
+		}
+		if !strings.EqualFold(result.Address, expected) {
+			t.Errorf("contract address %s, want %s", result.Address, expected)
+		}
+	}
+	if _, err := deploy("0x0e50cea0402d2a396b0db1c5d08155bd219cc52e"); err == nil || !strings.Contains(err.Error(), "not the expected") {
+		t.Errorf("receipt of another address is accepted: %v", err)
+	}
+}
+
+func TestAbiRegistryOverlappingCode(t *testing.T) {
+	mock := thk.NewThk(&fakeProvider{})
+	token, err := mock.NewContract("constructor(uint256 supply)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	capped, err := mock.NewContract("constructor(uint256 supply, uint256 cap)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenArgs, err := token.GetInput("", big.NewInt(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cappedArgs, err := capped.GetInput("", big.NewInt(7), big.NewInt(9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := thk.NewAbiRegistry()
+	// the code of solc --bin without 0x, and the same code with appended metadata
+	registry.RegisterCode("6080", token)
+	registry.RegisterCode("0x6080A0B0", capped)
+
+	// the map order of the codes varies between the decodings
+	for i := 0; i < 20; i++ {
+		decoded, err := registry.Decode("", "0x6080a0b0"+cappedArgs[2:])
+		if err != nil || decoded.Map()["cap"].(*big.Int).Int64() != 9 {
+			t.Fatalf("decoded deploy of the longer code: %+v %v", decoded, err)
+		}
+		decoded, err = registry.Decode("", "0x6080"+tokenArgs[2:])
+		if err != nil || decoded.Map()["supply"].(*big.Int).Int64() != 7 {
+			t.Fatalf("decoded deploy of the shorter code: %+v %v", decoded, err)
+		}
+	}
+	if _, err := registry.Decode("", "0x6180"+tokenArgs[2:]); err != thk.ErrUnknownInput {
+		t.Errorf("unknown code is decoded: %v", err)
+	}
+}
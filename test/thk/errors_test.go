@@ -0,0 +1,70 @@
+package test
+
+import (
+	"errors"
+	"github.com/ThinkiumGroup/web3.go/web3/dto"
+	"github.com/ThinkiumGroup/web3.go/web3/thk"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"testing"
+)
+
+func TestNodeErrorClassification(t *testing.T) {
+	tests := []struct {
+		msg  string
+		kind error
+	}{
+		{"nonce is too low, expected 5", thk.ErrNonceTooLow},
+		{"Insufficient balance for transfer", thk.ErrInsufficientBalance},
+		{"transaction not found", thk.ErrTxNotFound},
+		{"chain not found: 999", thk.ErrChainNotFound},
+		{"something else", thk.ErrNodeError},
+	}
+	for _, test := range tests {
+		err := error(thk.NewNodeError("SendTx", "1", 0, test.msg))
+		if !errors.Is(err, test.kind) {
+			t.Errorf("%q is not %v", test.msg, test.kind)
+		}
+	}
+}
+
+func TestNodeErrorOfMethods(t *testing.T) {
+	provider := &fakeProvider{result: map[string]interface{}{"ErrMsg": "nonce too low"}}
+	mock := thk.NewThk(provider)
+	_, err := mock.SendTx(&util.Transaction{ChainId: "2"})
+	var nodeErr *thk.NodeError
+	if !errors.As(err, &nodeErr) || !errors.Is(err, thk.ErrNonceTooLow) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if nodeErr.Method != "SendTx" || nodeErr.ChainId != "2" || nodeErr.Message != "nonce too low" {
+		t.Errorf("unexpected node error %+v", nodeErr)
+	}
+
+	// methods which didn't check ErrMsg before
+	provider.result = map[string]interface{}{"errMsg": "chain not found"}
+	if _, err = mock.GetStats("9"); !errors.Is(err, thk.ErrChainNotFound) {
+		t.Errorf("GetStats: unexpected error %v", err)
+	}
+	if _, err = mock.GetCommittee("9", "1"); !errors.Is(err, thk.ErrChainNotFound) {
+		t.Errorf("GetCommittee: unexpected error %v", err)
+	}
+	if _, err = mock.GetChainInfo([]int{9}); !errors.Is(err, thk.ErrChainNotFound) {
+		t.Errorf("GetChainInfo: unexpected error %v", err)
+	}
+
+	// JSON-RPC error object
+	provider.result = map[string]interface{}{"error": dto.Error{Code: -32000, Message: "tx not found"}}
+	_, err = mock.GetTransactionByHash("1", "0x01")
+	if !errors.As(err, &nodeErr) || !errors.Is(err, thk.ErrTxNotFound) || nodeErr.Code != -32000 {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// results without error
+	provider.result = []string{"0x01", "0x02"}
+	if committee, err := mock.GetCommittee("1", "1"); err != nil || len(committee) != 2 {
+		t.Errorf("unexpected committee %v %v", committee, err)
+	}
+	provider.result = dto.GetChainStats{ChainId: 1, CurrentHeight: 10}
+	if stats, err := mock.GetStats("1"); err != nil || stats.CurrentHeight != 10 {
+		t.Errorf("unexpected stats %v %v", stats, err)
+	}
+}
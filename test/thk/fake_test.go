@@ -0,0 +1,81 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"sync"
+)
+
+// respond answers the nth request (from 1) of the fake provider by its params
+type respond func(n int, params interface{}) (interface{}, error)
+
+// fakeProvider answers each method by its result in results, or by result if the method has none.
+// A respond result is called for every request, and an error result fails the request. The
+// requests are recorded in order.
+type fakeProvider struct {
+	results map[string]interface{}
+	result  interface{}
+
+	lock     sync.Mutex
+	methods  []string
+	requests []interface{}
+}
+
+func (p *fakeProvider) SendRequest(v interface{}, method string, params interface{}) error {
+	p.lock.Lock()
+	p.methods = append(p.methods, method)
+	p.requests = append(p.requests, params)
+	n := len(p.methods)
+	result, ok := p.results[method]
+	if !ok {
+		result = p.result
+	}
+	p.lock.Unlock()
+	if f, ok := result.(respond); ok {
+		var err error
+		if result, err = f(n, params); err != nil {
+			return err
+		}
+	}
+	switch r := result.(type) {
+	case nil:
+		return fmt.Errorf("unexpected %s", method)
+	case error:
+		return r
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func (p *fakeProvider) Close() error {
+	return nil
+}
+
+// last returns the params of the last request of the method, nil if it's not sent
+func (p *fakeProvider) last(method string) interface{} {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for i := len(p.methods) - 1; i >= 0; i-- {
+		if p.methods[i] == method {
+			return p.requests[i]
+		}
+	}
+	return nil
+}
+
+// sent returns the transactions sent by SendTx
+func (p *fakeProvider) sent() []*util.Transaction {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	var txs []*util.Transaction
+	for i, method := range p.methods {
+		if method == "SendTx" {
+			txs = append(txs, p.requests[i].(*util.Transaction))
+		}
+	}
+	return txs
+}
@@ -0,0 +1,145 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ThinkiumGroup/go-common"
+	"github.com/ThinkiumGroup/web3.go/web3/dto"
+	"github.com/ThinkiumGroup/web3.go/web3/thk"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// forkChain is a chain whose blocks after a height can be replaced
+type forkChain struct {
+	lock    sync.Mutex
+	headers []*dto.BlockHeader
+}
+
+func newForkChain(head int) *forkChain {
+	c := &forkChain{}
+	c.fork(0, head, 0)
+	return c
+}
+
+// fork replaces the blocks from height from to height to by blocks tagged by the fork number
+func (c *forkChain) fork(from, to int, fork byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.headers = c.headers[:from]
+	for h := from; h <= to; h++ {
+		header := &dto.BlockHeader{ChainID: 1, Height: common.Height(h)}
+		header.StateRoot[0] = fork
+		if h > 0 {
+			hash, _ := c.headers[h-1].HashValue()
+			header.PreviousHash = common.BytesToHash(hash)
+		}
+		c.headers = append(c.headers, header)
+	}
+}
+
+// provider answers GetBlock and GetStats by the blocks of the chain
+func (c *forkChain) provider() *fakeProvider {
+	return &fakeProvider{results: map[string]interface{}{
+		"GetBlock": respond(func(n int, params interface{}) (interface{}, error) {
+			c.lock.Lock()
+			defer c.lock.Unlock()
+			height, _ := strconv.Atoi(params.(util.GetBlockHeader).Height)
+			if height >= len(c.headers) {
+				return nil, fmt.Errorf("block %d not found", height)
+			}
+			return dto.BlockDetail{BlockHeader: c.headers[height]}, nil
+		}),
+		"GetStats": respond(func(n int, params interface{}) (interface{}, error) {
+			c.lock.Lock()
+			defer c.lock.Unlock()
+			return dto.GetChainStats{ChainId: 1, CurrentHeight: len(c.headers) - 1}, nil
+		}),
+	}}
+}
+
+func pollFollower(t *testing.T, f *thk.ChainFollower) []thk.FollowEvent {
+	var all []thk.FollowEvent
+	for {
+		events, err := f.Poll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) == 0 {
+			return all
+		}
+		all = append(all, events...)
+	}
+}
+
+func TestChainFollowerRollback(t *testing.T) {
+	chain := newForkChain(10)
+	follower := thk.NewThk(chain.provider()).NewChainFollower("1", 3, nil)
+	follower.WindowSize = 5
+	events := pollFollower(t, follower)
+	if len(events) != 8 || events[7].Height != 10 {
+		t.Fatalf("expect blocks 3-10, but %d events", len(events))
+	}
+	window := follower.Window()
+	if len(window) != 5 || window[0].Height != 6 || window[4].Height != 10 {
+		t.Fatalf("unexpected window %+v", window)
+	}
+
+	// blocks 8-10 are replaced, and the new fork grows to 12
+	chain.fork(8, 12, 1)
+	events = pollFollower(t, follower)
+	if len(events) != 6 {
+		t.Fatalf("expect a rollback and blocks 8-12, but %d events", len(events))
+	}
+	rollback := events[0]
+	if rollback.Type != thk.FollowRollback || rollback.Height != 7 || rollback.Depth != 3 ||
+		len(rollback.Removed) != 3 || rollback.Removed[0] != window[2] {
+		t.Errorf("unexpected rollback %+v", rollback)
+	}
+	for i, event := range events[1:] {
+		if event.Type != thk.FollowNewBlock || event.Height != uint64(8+i) || event.Block.BlockHeader.StateRoot[0] != 1 {
+			t.Errorf("expect block %d of the fork, but %+v", 8+i, event)
+		}
+	}
+}
+
+func TestChainFollowerResume(t *testing.T) {
+	chain := newForkChain(6)
+	follower := thk.NewThk(chain.provider()).NewChainFollower("1", 0, nil)
+	pollFollower(t, follower)
+
+	// the saved window is replaced while the follower is not running
+	chain.fork(5, 8, 1)
+	resumed := thk.NewThk(chain.provider()).NewChainFollower("1", 0, follower.Window())
+	events := pollFollower(t, resumed)
+	if len(events) != 5 || events[0].Type != thk.FollowRollback || events[0].Height != 4 || events[0].Depth != 2 {
+		t.Errorf("unexpected events %+v", events)
+	}
+}
+
+func TestChainFollowerTooDeep(t *testing.T) {
+	chain := newForkChain(10)
+	follower := thk.NewThk(chain.provider()).NewChainFollower("1", 0, nil)
+	follower.WindowSize = 4
+	follower.PollInterval = 10 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := follower.Run(ctx)
+	for i := 0; i <= 10; i++ {
+		if event := <-events; event.Type != thk.FollowNewBlock || event.Height != uint64(i) {
+			t.Fatalf("expect block %d, but %+v", i, event)
+		}
+	}
+	chain.fork(5, 12, 1)
+	event := <-events
+	if event.Type != thk.FollowError || !errors.Is(event.Err, thk.ErrReorgTooDeep) {
+		t.Errorf("expect ErrReorgTooDeep, but %+v", event)
+	}
+	if _, ok := <-events; ok {
+		t.Error("follower should stop after a too deep reorg")
+	}
+}
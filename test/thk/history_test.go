@@ -0,0 +1,94 @@
+package test
+
+import (
+	"github.com/ThinkiumGroup/web3.go/web3/dto"
+	"github.com/ThinkiumGroup/web3.go/web3/thk"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"math/big"
+	"strconv"
+	"testing"
+)
+
+const historyAddress = "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23"
+
+// historyChain has a transaction of historyAddress at every height: received at even heights, sent
+// at odd heights, and a contract call at heights divisible by 5
+func historyChain() *fakeProvider {
+	return &fakeProvider{results: map[string]interface{}{
+		"GetTransactions": respond(func(n int, params interface{}) (interface{}, error) {
+			p := params.(util.GetTransactionsJson)
+			start, _ := strconv.ParseUint(p.StartHeight, 10, 64)
+			end, _ := strconv.ParseUint(p.EndHeight, 10, 64)
+			// the node returns a json array, and values exceed int64
+			value, _ := new(big.Int).SetString("123456789000000000000000000", 10)
+			var txs []dto.GetTransactions
+			for h := start; h <= end; h++ {
+				tx := dto.GetTransactions{ChainId: 2, From: "0x0000000000000000000000000000000000000001",
+					To: historyAddress, Nonce: int(h), Value: value, Hash: strconv.FormatUint(h, 10)}
+				if h%2 == 1 {
+					tx.From, tx.To = historyAddress, "0x0000000000000000000000000000000000000002"
+				}
+				if h%5 == 0 {
+					tx.Input = "0x01"
+				}
+				txs = append(txs, tx)
+			}
+			return txs, nil
+		}),
+	}}
+}
+
+func TestGetAddressHistory(t *testing.T) {
+	chain := historyChain()
+	txs, err := thk.NewThk(chain).GetAddressHistory("2", historyAddress, 10, 34, thk.HistoryOptions{PageHeights: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pages [][2]string
+	for _, params := range chain.requests {
+		p := params.(util.GetTransactionsJson)
+		pages = append(pages, [2]string{p.StartHeight, p.EndHeight})
+	}
+	if len(pages) != 3 || pages[0] != [2]string{"10", "19"} || pages[2] != [2]string{"30", "34"} {
+		t.Errorf("unexpected pages %v", pages)
+	}
+	if len(txs) != 25 || txs[0].Nonce != 10 || txs[24].Nonce != 34 {
+		t.Fatalf("expect transactions 10-34, but %d", len(txs))
+	}
+	if txs[0].Value.String() != "123456789000000000000000000" {
+		t.Errorf("value decoded as %s", txs[0].Value)
+	}
+}
+
+func TestGetAddressHistoryDirection(t *testing.T) {
+	mock := thk.NewThk(historyChain())
+	cases := []struct {
+		direction thk.TxDirection
+		count     int
+	}{
+		{thk.TxIncoming, 10},                      // even heights in 1-20
+		{thk.TxOutgoing, 10},                      // odd heights
+		{thk.TxContractCall, 4},                   // 5, 10, 15, 20
+		{thk.TxIncoming | thk.TxContractCall, 12}, // even heights, 5 and 15
+		{thk.TxAnyDirection, 20},
+	}
+	for _, c := range cases {
+		txs, err := mock.GetAddressHistory("2", historyAddress, 1, 20, thk.HistoryOptions{PageHeights: 7, Direction: c.direction})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(txs) != c.count {
+			t.Errorf("expect %d transactions of direction %d, but %d", c.count, c.direction, len(txs))
+		}
+	}
+}
+
+func TestGetTransactionsArray(t *testing.T) {
+	res, err := thk.NewThk(historyChain()).GetTransactions("2", historyAddress, "1", "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 3 {
+		t.Errorf("expect 3 transactions, but %d", len(res))
+	}
+}
@@ -0,0 +1,117 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"github.com/ThinkiumGroup/go-common"
+	"github.com/ThinkiumGroup/web3.go/test"
+	"github.com/ThinkiumGroup/web3.go/web3/dto"
+	"github.com/ThinkiumGroup/web3.go/web3/thk"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"math/rand"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mockChain is a chain whose head can be moved, block h has h transactions
+type mockChain struct {
+	head int64
+}
+
+// provider answers GetBlock, GetBlockTxs and GetStats by the chain
+func (m *mockChain) provider() *fakeProvider {
+	return &fakeProvider{results: map[string]interface{}{
+		"GetBlock": respond(func(n int, params interface{}) (interface{}, error) {
+			time.Sleep(time.Duration(rand.Intn(5)) * time.Millisecond)
+			height, _ := strconv.ParseUint(params.(util.GetBlockHeader).Height, 10, 64)
+			return dto.BlockDetail{BlockHeader: &dto.BlockHeader{ChainID: 1, Height: common.Height(height)}}, nil
+		}),
+		"GetBlockTxs": respond(func(n int, params interface{}) (interface{}, error) {
+			p := params.(util.GetBlockTxsJson)
+			height, _ := strconv.Atoi(p.Height)
+			page, _ := strconv.Atoi(p.Page)
+			size, _ := strconv.Atoi(p.Size)
+			var txs []dto.TransactionResult
+			for i := (page - 1) * size; i < page*size && i < height; i++ {
+				txs = append(txs, dto.TransactionResult{Nonce: i, Hash: fmt.Sprintf("%d-%d", height, i)})
+			}
+			return dto.BlockTxs{AccountChanges: txs}, nil
+		}),
+		"GetStats": respond(func(n int, params interface{}) (interface{}, error) {
+			return dto.GetChainStats{ChainId: 1, CurrentHeight: int(atomic.LoadInt64(&m.head))}, nil
+		}),
+	}}
+}
+
+func TestIterateBlocks(t *testing.T) {
+	mock := thk.NewThk(new(mockChain).provider())
+	expected := uint64(10)
+	for item := range mock.IterateBlocks(context.Background(), "1", 10, 40, thk.IterateOptions{Concurrency: 8}) {
+		if item.Err != nil {
+			t.Error(item.Err)
+			t.FailNow()
+		}
+		if item.Height != expected || uint64(item.Block.BlockHeader.Height) != expected {
+			t.Errorf("expect block %d, but %d", expected, item.Height)
+		}
+		expected++
+	}
+	if expected != 41 {
+		t.Errorf("iteration stopped at %d", expected)
+	}
+}
+
+func TestIterateTxs(t *testing.T) {
+	mock := thk.NewThk(new(mockChain).provider())
+	count := 0
+	for item := range mock.IterateTxs(context.Background(), "1", 0, 12, thk.IterateOptions{PageSize: 5}) {
+		if item.Err != nil {
+			t.Error(item.Err)
+			t.FailNow()
+		}
+		expected := fmt.Sprintf("%d-%d", item.Height, item.Tx.Nonce)
+		if item.Tx.Hash != expected {
+			t.Errorf("expect %s, but %s", expected, item.Tx.Hash)
+		}
+		count++
+	}
+	// block h has h transactions
+	if count != 12*13/2 {
+		t.Errorf("expect %d transactions, but %d", 12*13/2, count)
+	}
+}
+
+func TestIterateBlocksFollowHead(t *testing.T) {
+	chain := &mockChain{head: 5}
+	mock := thk.NewThk(chain.provider())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	items := mock.IterateBlocks(ctx, "1", 3, thk.FollowHead, thk.IterateOptions{PollInterval: 10 * time.Millisecond})
+	for expected := uint64(3); expected <= 10; expected++ {
+		if expected == 6 {
+			atomic.StoreInt64(&chain.head, 10)
+		}
+		item, ok := <-items
+		if !ok || item.Err != nil || item.Height != expected {
+			t.Errorf("expect block %d, but %+v", expected, item)
+			t.FailNow()
+		}
+	}
+	cancel()
+	for range items {
+	}
+}
+
+func TestIterateChainBlocks(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	for item := range test.Web3.Thk.IterateBlocks(ctx, "1", 983910, 983918) {
+		if item.Err != nil {
+			t.Error(item.Err)
+			t.FailNow()
+		}
+		t.Logf("block %d: %x", item.Height, item.Block.Hash().Bytes())
+	}
+}
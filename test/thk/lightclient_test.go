@@ -0,0 +1,138 @@
+package test
+
+import (
+	"encoding/hex"
+	"errors"
+	"github.com/ThinkiumGroup/go-common"
+	common2 "github.com/ThinkiumGroup/web3.go/common"
+	"github.com/ThinkiumGroup/web3.go/test"
+	"github.com/ThinkiumGroup/web3.go/web3/dto"
+	"github.com/ThinkiumGroup/web3.go/web3/lightclient"
+	"github.com/ThinkiumGroup/web3.go/web3/thk"
+	"testing"
+)
+
+func TestVerifiedGetBlock(t *testing.T) {
+	checkpoint, err := lightclient.CheckpointFromNode(test.Web3.Thk, "1", 983)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	lc, err := lightclient.New(test.Web3.Thk, "1", checkpoint)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	block, err := lc.VerifiedGetBlock(983918)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	t.Logf("block %d verified: %x", block.BlockHeader.Height, block.Hash().Bytes())
+}
+
+// member is a committee member signing the blocks with its key
+type member struct {
+	key string
+	id  common.NodeID
+	pub dto.Public
+}
+
+func newMember(t *testing.T, key string) *member {
+	priv, err := common2.HexToPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := priv.GetPublicKey().ToBytes()
+	m := &member{key: key}
+	copy(m.pub[:], pub)
+	copy(m.id[:], pub[1:])
+	return m
+}
+
+func (m *member) sign(t *testing.T, header *dto.BlockHeader) *dto.PubAndSig {
+	hash, err := common.HashObject(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := common2.HexToPrivateKey(m.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := common2.Cipher.Sign(common2.Cipher.PrivToBytes(priv), hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pas := &dto.PubAndSig{PublicKey: m.pub}
+	copy(pas.Signature[:], sig)
+	return pas
+}
+
+func newMembers(t *testing.T) (a, b, c, d *member) {
+	return newMember(t, "0x8e5b44b6cee8fa05092b4b5a8843aa6b0ec37915a940c9b5938e88a7e6fdd83a"),
+		newMember(t, "0x7b3effbc3292e156d1993f8327e6e5d9fe776a5494bc911baee53aa1db0be6d6"),
+		newMember(t, "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"),
+		newMember(t, "0x2a871d0798f97d79848a013d4936a73bf4cc922c825d33c1cf7073dff6d409c6")
+}
+
+func TestCountCommitteeSignatures(t *testing.T) {
+	a, b, c, d := newMembers(t)
+	committee := []common.NodeID{a.id, b.id, c.id}
+	header := &dto.BlockHeader{ChainID: 1, Height: 10}
+	other := &dto.BlockHeader{ChainID: 1, Height: 11}
+	pass := dto.PubAndSigs{
+		a.sign(t, header),
+		a.sign(t, header), // duplicated signer is counted once
+		d.sign(t, header), // not a member
+		c.sign(t, other),  // signature of another block
+		nil,
+	}
+	count, err := lightclient.CountCommitteeSignatures(header, pass, committee)
+	if !errors.Is(err, lightclient.ErrNoQuorum) || count != 1 {
+		t.Errorf("should be no quorum with 1 signature, but count:%d err:%v", count, err)
+	}
+	count, err = lightclient.CountCommitteeSignatures(header, append(pass, b.sign(t, header)), committee)
+	if err != nil || count != 2 {
+		t.Errorf("2 of 3 members signed, but count:%d err:%v", count, err)
+	}
+}
+
+func TestNextCommitteeRoot(t *testing.T) {
+	a, b, c, d := newMembers(t)
+	elected := &dto.Committee{Members: []common.NodeID{b.id, c.id, d.id}}
+	root, err := elected.HashValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	electedRoot := common.BytesToHash(root)
+	committee := func(body *dto.BlockBody) ([]common.NodeID, error) {
+		header := &dto.BlockHeader{ChainID: 1, Height: 59, ElectedNextRoot: &electedRoot}
+		block := &dto.BlockDetail{BlockHeader: header, BlockBody: body,
+			BlockPass: dto.PubAndSigs{a.sign(t, header), b.sign(t, header), c.sign(t, header)}}
+		provider := &fakeProvider{results: map[string]interface{}{
+			"GetStats": map[string]interface{}{"epochlength": 10},
+			"GetBlock": block,
+		}}
+		checkpoint := lightclient.Checkpoint{Epoch: 5, Members: []string{
+			hex.EncodeToString(a.id[:]), hex.EncodeToString(b.id[:]), hex.EncodeToString(c.id[:])}}
+		lc, err := lightclient.New(thk.NewThk(provider), "1", checkpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return lc.Committee(6)
+	}
+
+	members, err := committee(&dto.BlockBody{NextCommittee: elected})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 3 || members[0] != b.id || members[1] != c.id || members[2] != d.id {
+		t.Errorf("next committee %x, want the elected one", members)
+	}
+	forged := &dto.Committee{Members: []common.NodeID{a.id, b.id, d.id}}
+	for _, body := range []*dto.BlockBody{{NextCommittee: forged}, {NextCommittee: elected, NextRealCommittee: forged}} {
+		if _, err := committee(body); !errors.Is(err, lightclient.ErrCommitteeRoot) {
+			t.Errorf("forged committee should be rejected, but %v", err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"bytes"
+	"github.com/ThinkiumGroup/web3.go/common"
+	"github.com/ThinkiumGroup/web3.go/common/hexutil"
+	"github.com/ThinkiumGroup/web3.go/web3/thk"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"sync"
+	"testing"
+)
+
+func networkTx() *util.Transaction {
+	return &util.Transaction{ChainId: "1", FromChainId: "1", ToChainId: "1", From: "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23",
+		To: "0x0e50cea0402d2a396b0db1c5d08155bd219cc52e", Nonce: "1", Value: "1"}
+}
+
+func TestNetworkHash(t *testing.T) {
+	mainnet, testnet := util.NewNetwork("main", 70000), util.NewNetwork("test", 60000)
+	mainHash, err := networkTx().HashValue(mainnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testHash, err := networkTx().HashValue(testnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(mainHash, testHash) {
+		t.Error("hashes of different networks are equal")
+	}
+	defaultHash, _ := networkTx().HashValue(nil)
+	if h, _ := networkTx().HashValue(util.NewNetwork("", util.DefaultBaseChainId)); !bytes.Equal(defaultHash, h) {
+		t.Error("nil is not the default network")
+	}
+
+	testnet.Chains = []common.ChainId{0, 2}
+	if _, err := networkTx().HashValue(testnet); err == nil {
+		t.Error("unknown chain is hashed")
+	}
+}
+
+// clients of different networks sign concurrently without affecting each other
+func TestNetworkPerClient(t *testing.T) {
+	key := "0x8e5b44b6cee8fa05092b4b5a8843aa6b0ec37915a940c9b5938e88a7e6fdd83a"
+	clients := []*thk.Thk{
+		thk.NewThkOnNetwork(&fakeProvider{}, util.NewNetwork("main", 70000)),
+		thk.NewThkOnNetwork(&fakeProvider{}, util.NewNetwork("test", 60000)),
+	}
+	var wg sync.WaitGroup
+	sigs := make([][]string, len(clients))
+	for i, client := range clients {
+		wg.Add(1)
+		go func(i int, client *thk.Thk) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				tx := networkTx()
+				if err := client.SignTransaction(tx, key); err != nil {
+					t.Error(err)
+					return
+				}
+				sigs[i] = append(sigs[i], tx.Sig)
+			}
+		}(i, client)
+	}
+	wg.Wait()
+	priv, err := common.HexToPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := priv.GetPublicKey().ToBytes()
+	for i, client := range clients {
+		want, err := networkTx().HashValue(client.Network)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, sig := range sigs[i] {
+			b, err := hexutil.Decode(sig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			recovered, err := common.Cipher.RecoverPub(want, b)
+			if err != nil || !bytes.Equal(recovered, pub) {
+				t.Errorf("signature of %s is not signed by the key over its hash: %v", client.Network, err)
+			}
+		}
+	}
+	if sigs[0][0] == sigs[1][0] {
+		t.Error("signatures of different networks are equal")
+	}
+}
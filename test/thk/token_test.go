@@ -7,6 +7,7 @@ import (
 	"github.com/ThinkiumGroup/web3.go/common/hexutil"
 	"github.com/ThinkiumGroup/web3.go/test"
 	"github.com/ThinkiumGroup/web3.go/test/compiler"
+	"github.com/ThinkiumGroup/web3.go/web3/dto"
 	"github.com/ThinkiumGroup/web3.go/web3/thk"
 	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
 	"io/ioutil"
@@ -112,24 +113,17 @@ func TestERC20Deploy(t *testing.T) {
 		To: "", Value: "0", Input: "", Nonce: strconv.Itoa(int(nonce)),
 	}
 
-	hash, err := erc20.Deploy(transaction, erc20Bytecode, test.TmpKey, Symbol, Name, decimals, totalSupply)
+	result, err := erc20.DeployAndWait(transaction, erc20Bytecode, test.TmpKey, Symbol, Name, decimals, totalSupply)
 	if err != nil {
 		t.Error(err)
 		t.FailNow()
 	}
-	t.Log("erc20 hash:", hash)
-
-	receipt, err := test.BlockGetTransactionReceipt(chainId, hash)
-	if err != nil {
-		t.Error(err)
-		t.FailNow()
-	}
-	if receipt.Status == 0 {
-		t.Error(fmt.Sprintf("failed tx, hash:%v", hash))
-		t.FailNow()
-	}
-	erc20Address := receipt.ContractAddress
+	t.Log("erc20 hash:", result.Hash)
+	erc20Address := result.Address
 	t.Log("erc20Address addr:", erc20Address)
+	if !strings.EqualFold(erc20Address, result.ExpectedAddress) {
+		t.Errorf("contract address %s is not the expected %s", erc20Address, result.ExpectedAddress)
+	}
 	var symbol string
 	err = erc20.CallAndParse(chainId, erc20Address, &symbol, "symbol")
 	if err != nil {
@@ -212,7 +206,7 @@ func TestErc20Approve(t *testing.T) {
 
 //Transfer
 func TestErc20Transfer(t *testing.T) {
-	thk.SetBaseChainId(60000)
+	test.Web3.Thk.Network = util.NewNetwork("test", 60000)
 	bytes, err := hexutil.Decode(strings.ToLower(tokenVestingAddress))
 	if err != nil {
 		t.Error(err)
@@ -324,6 +318,49 @@ func checkTransaction(t *testing.T, hash string) {
 	}
 }
 
+func TestDecodeErc20Input(t *testing.T) {
+	input, err := erc20.GetInput("transfer", fromAddress, approveAmount)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	decoded, err := erc20.DecodeInput(input)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	t.Log("decoded:", test.JsonFormat(decoded))
+	args := decoded.Map()
+	if decoded.Name != "transfer" || args["recipient"] != fromAddress || args["amount"].(*big.Int).Cmp(approveAmount) != 0 {
+		t.Errorf("unexpected decoded input: %+v", decoded)
+	}
+
+	deployArgs, err := erc20.GetInput("", Symbol, Name, decimals, totalSupply)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	deployInput := erc20Bytecode + deployArgs[2:]
+	registry := thk.NewAbiRegistry()
+	registry.Register(erc20Address, erc20)
+	registry.RegisterCode(erc20Bytecode, erc20)
+	txs := &dto.BlockTxs{AccountChanges: []dto.TransactionResult{
+		{To: erc20Address, Input: input},
+		{To: "", Input: deployInput},
+		{To: test.TmpAddress, Input: "0x"},
+	}}
+	results := registry.DecodeBlockTxs(txs)
+	if results[0].Err != nil || results[0].Input.Name != "transfer" {
+		t.Errorf("decode transfer failed: %v", results[0].Err)
+	}
+	if results[1].Err != nil || results[1].Input.Map()["_tokenSymbol"] != Symbol {
+		t.Errorf("decode deploy failed: %v %+v", results[1].Err, results[1].Input)
+	}
+	if results[2].Err != thk.ErrUnknownInput {
+		t.Errorf("transfer without input should not be decoded")
+	}
+}
+
 func loadContract(jsonFileLocation string) (*thk.Contract, string, error) {
 	file, err := ioutil.ReadFile(jsonFileLocation)
 	if err != nil {
@@ -1,16 +1,18 @@
 package test
 
 import (
+	"errors"
 	"fmt"
 	"github.com/ThinkiumGroup/web3.go/test"
 	"github.com/ThinkiumGroup/web3.go/web3/thk"
 	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"math/big"
 	"strconv"
 	"testing"
 )
 
 func TestSendTx(t *testing.T) {
-	thk.SetBaseChainId(60000)
+	test.Web3.Thk.Network = util.NewNetwork("test", 60000)
 	var err error
 	to := test.TmpAddress
 	account, err := test.Web3.Thk.GetAccount(test.Web3.Thk.DefaultAddress, test.Web3.Thk.DefaultChainId)
@@ -48,3 +50,75 @@ func TestGetTransactionByHash(t *testing.T) {
 	}
 	fmt.Printf("res:%+v", test.JsonFormat(res))
 }
+
+func TestEstimateGas(t *testing.T) {
+	account, err := test.Web3.Thk.GetAccount(test.Web3.Thk.DefaultAddress, test.Web3.Thk.DefaultChainId)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	transaction := util.Transaction{
+		ChainId: test.Web3.Thk.DefaultChainId, FromChainId: test.Web3.Thk.DefaultChainId, ToChainId: test.Web3.Thk.DefaultChainId, From: test.Web3.Thk.DefaultAddress,
+		To: test.TmpAddress, Value: test.DefaultValue, Input: "", Nonce: strconv.Itoa(int(account.Nonce)), UseLocal: false, Extra: "",
+	}
+	fee, err := test.Web3.Thk.FillGas(&transaction)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	gasProvider, err := transaction.GasProvider()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if gasProvider.Fee().Cmp(fee) != 0 {
+		t.Errorf("fee mismatch: %s != %s", gasProvider.Fee(), fee)
+	}
+	t.Logf("gas:%d gasPrice:%s fee:%s", gasProvider.Gas, gasProvider.GasPrice, fee)
+}
+
+func TestFillGas(t *testing.T) {
+	provider := &fakeProvider{results: map[string]interface{}{
+		"CallTransaction": map[string]interface{}{"status": 1, "gasUsed": 50000},
+		"GetStats":        map[string]interface{}{"gasprice": "400000000"},
+	}}
+	mock := thk.NewThk(provider)
+	tx := util.Transaction{ChainId: "1", FromChainId: "1", ToChainId: "1", From: "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23",
+		To: "0x0e50cea0402d2a396b0db1c5d08155bd219cc52e", Value: "0", Nonce: "1", Input: "0x01"}
+	if err := tx.SetGasProvider(&util.GasProvider{Gas: 1, GasPrice: big.NewInt(1)}); err != nil {
+		t.Fatal(err)
+	}
+	fee, err := mock.FillGas(&tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if call, ok := provider.last("CallTransaction").(*util.Transaction); !ok || call.Extra != "" || call.Input != "0x01" {
+		t.Errorf("unexpected call %+v", provider.last("CallTransaction"))
+	}
+	gasProvider, err := tx.GasProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 20% margin of the gas used
+	if gasProvider.Gas != 60000 || gasProvider.GasPrice.String() != "400000000" {
+		t.Errorf("unexpected gas %+v", gasProvider)
+	}
+	if fee.String() != "24000000000000" || gasProvider.Fee().Cmp(fee) != 0 {
+		t.Errorf("unexpected fee %s", fee)
+	}
+
+	// no default unless it's set
+	provider.results["CallTransaction"] = map[string]interface{}{"status": 1}
+	provider.results["GetStats"] = map[string]interface{}{"currentheight": 1}
+	if _, err := mock.EstimateGas(&tx); !errors.Is(err, thk.ErrNoGasUsed) {
+		t.Errorf("estimated without gas used: %v", err)
+	}
+	if _, err := mock.GasPrice("1"); !errors.Is(err, thk.ErrNoGasPrice) {
+		t.Errorf("gas price without stats: %v", err)
+	}
+	mock.DefaultGas = util.DefaultGasProvider
+	if gasProvider, err := mock.SuggestGas(&tx); err != nil || gasProvider.Gas != util.DefaultGasProvider.Gas ||
+		gasProvider.GasPrice.Cmp(util.DefaultGasProvider.GasPrice) != 0 {
+		t.Errorf("unexpected default gas %+v %v", gasProvider, err)
+	}
+}
@@ -0,0 +1,110 @@
+package test
+
+import (
+	"github.com/ThinkiumGroup/web3.go/common"
+	"github.com/ThinkiumGroup/web3.go/web3/thk"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"math/big"
+	"testing"
+)
+
+func TestTypedTransactionRoundTrip(t *testing.T) {
+	to := common.HexToAddress("0x0e50cea0402d2a396b0db1c5d08155bd219cc52e")
+	value, _ := new(big.Int).SetString("100000000000000000000000", 10)
+	typed := &util.TypedTransaction{
+		ChainId: 1,
+		From:    common.HexToAddress("0x2c7536e3605d9c16a7a3d7b1898e529396a65c23"),
+		To:      &to,
+		Nonce:   42,
+		Value:   value,
+		Input:   []byte{0x01, 0x02},
+		Gas:     &util.GasProvider{Gas: 30000, GasPrice: big.NewInt(400000000)},
+	}
+	tx, err := typed.Transaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.ChainId != "1" || tx.FromChainId != "1" || tx.ToChainId != "1" || tx.Nonce != "42" ||
+		tx.Value != "100000000000000000000000" || tx.To != "0x0e50cea0402d2a396b0db1c5d08155bd219cc52e" || tx.Input != "0x0102" {
+		t.Errorf("unexpected transaction %+v", tx)
+	}
+	back, err := tx.Typed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.From != typed.From || *back.To != to || back.Nonce != 42 || back.Value.Cmp(value) != 0 ||
+		back.Gas.Gas != 30000 || back.Gas.GasPrice.Cmp(typed.Gas.GasPrice) != 0 {
+		t.Errorf("unexpected typed transaction %+v", back)
+	}
+}
+
+func TestTypedTransactionMainChain(t *testing.T) {
+	main := common.ChainId(0)
+	typed := &util.TypedTransaction{
+		ChainId:   1,
+		ToChainId: &main,
+		From:      common.HexToAddress("0x2c7536e3605d9c16a7a3d7b1898e529396a65c23"),
+		To:        &common.Address{},
+		Value:     big.NewInt(1),
+	}
+	tx, err := typed.Transaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.ChainId != "1" || tx.FromChainId != "1" || tx.ToChainId != "0" {
+		t.Errorf("transfer from chain 1 to 0 is converted to %+v", tx)
+	}
+	back, err := tx.Typed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.FromChainId == nil || *back.FromChainId != 1 || back.ToChainId == nil || *back.ToChainId != 0 {
+		t.Errorf("chain ids of the transfer are parsed as %v %v", back.FromChainId, back.ToChainId)
+	}
+}
+
+func TestTypedTransactionInvalid(t *testing.T) {
+	valid := util.Transaction{ChainId: "1", From: "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23", Nonce: "1", Value: "1"}
+	if _, err := valid.Typed(); err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]func(tx *util.Transaction){
+		"chainId":  func(tx *util.Transaction) { tx.ChainId = "a" },
+		"reserved": func(tx *util.Transaction) { tx.ChainId = "1048576" },
+		"from":     func(tx *util.Transaction) { tx.From = "0x2c75" },
+		"to":       func(tx *util.Transaction) { tx.To = "2c7536e3605d9c16a7a3d7b1898e529396a65c23" },
+		"nonce":    func(tx *util.Transaction) { tx.Nonce = "-1" },
+		"value":    func(tx *util.Transaction) { tx.Value = "1.5" },
+		"input":    func(tx *util.Transaction) { tx.Input = "0xzz" },
+	}
+	for name, modify := range cases {
+		tx := valid
+		modify(&tx)
+		if _, err := tx.Typed(); err == nil {
+			t.Errorf("%s: malformed transaction is parsed", name)
+		}
+	}
+	if _, err := (&util.TypedTransaction{ChainId: 1, Value: big.NewInt(-1)}).Transaction(); err == nil {
+		t.Error("negative value is accepted")
+	}
+}
+
+func TestTypedGetBalance(t *testing.T) {
+	provider := &fakeProvider{results: map[string]interface{}{"GetAccount": util.Account{Balance: big.NewInt(7), Nonce: 3}}}
+	typed := thk.NewThk(provider).Typed()
+	address := common.HexToAddress("0x2c7536e3605d9c16a7a3d7b1898e529396a65c23")
+	balance, err := typed.GetBalance(address, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, ok := provider.last("GetAccount").(util.GetAccountJson)
+	if !ok || params.Address != "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23" || params.ChainId != "2" {
+		t.Errorf("unexpected params %+v", provider.requests)
+	}
+	if balance.Int64() != 7 {
+		t.Errorf("expect balance 7, but %s", balance)
+	}
+	if nonce, err := typed.GetNonce(address, 2); err != nil || nonce != 3 {
+		t.Errorf("expect nonce 3, but %d %v", nonce, err)
+	}
+}
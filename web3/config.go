@@ -0,0 +1,434 @@
+package web3
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ThinkiumGroup/web3.go/common"
+	"github.com/ThinkiumGroup/web3.go/web3/keystore"
+	"github.com/ThinkiumGroup/web3.go/web3/providers"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"io/ioutil"
+	"os"
+	"sigs.k8s.io/yaml"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// EnvPrefix is the prefix of the environment variables overriding the config
+	EnvPrefix = "THK_"
+
+	// DefaultPasswordEnv is the environment variable of the keystore password if the signer has
+	// neither PasswordFile nor PasswordEnv
+	DefaultPasswordEnv = EnvPrefix + "KEYSTORE_PASSWORD"
+
+	defaultTimeout = 10 * time.Second
+)
+
+// Duration is a time.Duration in the config, such as "1.5s" or "200ms", or a number in seconds
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		var seconds float64
+		if err := json.Unmarshal(b, &seconds); err != nil {
+			return fmt.Errorf("invalid duration %s", b)
+		}
+		*d = Duration(seconds * float64(time.Second))
+		return nil
+	}
+	v, err := parseDuration(s)
+	*d = Duration(v)
+	return err
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	return time.ParseDuration(s)
+}
+
+// EndpointConfig is the node of the chains, and the options of its provider
+type EndpointConfig struct {
+	Address     string       `json:"address"`     // host[:port], or a url with "http://" or "https://"
+	Secure      bool         `json:"secure"`      // https, set by the scheme of the address
+	Timeout     Duration     `json:"timeout"`     // timeout of the requests, the Timeout of Config by default
+	RateLimit   float64      `json:"rateLimit"`   // requests per second, 0 is unlimited
+	Burst       int          `json:"burst"`       // burst of the rate limit
+	MaxInFlight int          `json:"maxInFlight"` // requests sent concurrently, 0 is unlimited
+	Cache       *CacheConfig `json:"cache"`       // caches the immutable results if it's not nil
+}
+
+type CacheConfig struct {
+	Size          int    `json:"size"`          // entries in memory, 1024 by default
+	Dir           string `json:"dir"`           // directory persisting the results, empty for memory only
+	Confirmations uint64 `json:"confirmations"` // blocks at least confirmations below the head are cached
+}
+
+// SignerConfig refers to the keystore file of a signing key, raw private keys are not accepted in
+// the config
+type SignerConfig struct {
+	Keystore     string `json:"keystore"`     // path of the keystore file
+	PasswordFile string `json:"passwordFile"` // file of the password
+	PasswordEnv  string `json:"passwordEnv"`  // environment variable of the password
+	Address      string `json:"address"`      // expected address of the key, not checked if empty
+}
+
+// NetworkConfig is the Network of Thk, util.DefaultNetwork with the fields set
+type NetworkConfig struct {
+	Name            string                `json:"name"`
+	BaseChainId     int64                 `json:"baseChainId"`     // DefaultBaseChainId if 0
+	Chains          []common.ChainId      `json:"chains"`          // known chains, any chain is accepted if empty
+	SystemContracts *util.SystemContracts `json:"systemContracts"` // DefaultSystemContracts if nil
+}
+
+// Network returns the Network of the config
+func (n NetworkConfig) Network() *util.Network {
+	network := util.DefaultNetwork()
+	if n.Name != "" {
+		network.Name = n.Name
+	}
+	if n.BaseChainId != 0 {
+		network.BaseChainId = n.BaseChainId
+	}
+	network.Chains = n.Chains
+	if n.SystemContracts != nil {
+		network.SystemContracts = *n.SystemContracts
+	}
+	return network
+}
+
+type RetryConfig struct {
+	MaxRetries int      `json:"maxRetries"` // retries of the requests failed by the transport, 0 is no retry
+	Backoff    Duration `json:"backoff"`    // 200ms by default
+	MaxBackoff Duration `json:"maxBackoff"` // 5s by default
+}
+
+// Config describes the nodes, signers and policies of a Web3 client. It's loaded from a YAML or JSON
+// file by LoadConfig, or from the environment variables by ConfigFromEnv.
+type Config struct {
+	Endpoint     EndpointConfig            `json:"endpoint"`     // node of the chains without endpoints
+	Chains       map[string]EndpointConfig `json:"chains"`       // nodes of the chains by chain id
+	ChainId      json.Number               `json:"chainId"`      // DefaultChainId of Thk
+	Network      NetworkConfig             `json:"network"`      // Network of Thk
+	Signer       *SignerConfig             `json:"signer"`       // DefaultPrivateKey and DefaultAddress of Thk
+	ExtraSigners []SignerConfig            `json:"extraSigners"` // DefaultExtraPrivateKeys of Thk for the multi-signatures
+	Timeout      Duration                  `json:"timeout"`      // 10s by default
+	Retry        RetryConfig               `json:"retry"`
+}
+
+// LoadConfig loads the config from the YAML file, or the JSON file if its name ends with ".json",
+// and then overrides it by the environment variables
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config *Config
+	if strings.HasSuffix(strings.ToLower(path), ".json") {
+		config, err = ParseJSONConfig(data)
+	} else {
+		config, err = ParseYAMLConfig(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err = config.LoadEnv(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// ConfigFromEnv loads the config from the environment variables only
+func ConfigFromEnv() (*Config, error) {
+	config := new(Config)
+	if err := config.LoadEnv(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func ParseJSONConfig(data []byte) (*Config, error) {
+	config := new(Config)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func ParseYAMLConfig(data []byte) (*Config, error) {
+	b, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJSONConfig(b)
+}
+
+// LoadEnv overrides the config by the environment variables:
+//
+//	THK_ENDPOINT                  address of the default endpoint
+//	THK_TIMEOUT                   timeout of the requests
+//	THK_CHAIN_ID                  default chain id
+//	THK_BASE_CHAIN_ID             base chain id
+//	THK_KEYSTORE                  keystore file of the signer
+//	THK_KEYSTORE_PASSWORD_FILE    password file of the signer
+//	THK_RETRY_MAX                 max retries
+//	THK_RETRY_BACKOFF             first backoff of the retries
+//	THK_CHAIN_<ID>_ENDPOINT       address of the endpoint of the chain
+func (c *Config) LoadEnv() error {
+	var err error
+	env := func(name string, set func(string) error) {
+		if value, ok := os.LookupEnv(EnvPrefix + name); ok && err == nil {
+			if e := set(value); e != nil {
+				err = fmt.Errorf("%s%s: %v", EnvPrefix, name, e)
+			}
+		}
+	}
+	env("ENDPOINT", func(s string) error {
+		c.Endpoint.Address = s
+		return nil
+	})
+	env("TIMEOUT", func(s string) error {
+		d, e := parseDuration(s)
+		c.Timeout = Duration(d)
+		return e
+	})
+	env("CHAIN_ID", func(s string) error {
+		c.ChainId = json.Number(s)
+		return nil
+	})
+	env("BASE_CHAIN_ID", func(s string) (e error) {
+		c.Network.BaseChainId, e = strconv.ParseInt(s, 10, 64)
+		return e
+	})
+	env("KEYSTORE", func(s string) error {
+		if c.Signer == nil {
+			c.Signer = new(SignerConfig)
+		}
+		c.Signer.Keystore = s
+		return nil
+	})
+	env("KEYSTORE_PASSWORD_FILE", func(s string) error {
+		if c.Signer == nil {
+			return errors.New("no signer")
+		}
+		c.Signer.PasswordFile = s
+		return nil
+	})
+	env("RETRY_MAX", func(s string) (e error) {
+		c.Retry.MaxRetries, e = strconv.Atoi(s)
+		return e
+	})
+	env("RETRY_BACKOFF", func(s string) error {
+		d, e := parseDuration(s)
+		c.Retry.Backoff = Duration(d)
+		return e
+	})
+	for _, kv := range os.Environ() {
+		name := strings.SplitN(kv, "=", 2)[0]
+		if !strings.HasPrefix(name, EnvPrefix+"CHAIN_") || !strings.HasSuffix(name, "_ENDPOINT") {
+			continue
+		}
+		chainId := strings.TrimSuffix(strings.TrimPrefix(name, EnvPrefix+"CHAIN_"), "_ENDPOINT")
+		if chainId == "" {
+			continue
+		}
+		env(strings.TrimPrefix(name, EnvPrefix), func(s string) error {
+			if c.Chains == nil {
+				c.Chains = make(map[string]EndpointConfig)
+			}
+			endpoint := c.Chains[chainId]
+			endpoint.Address = s
+			c.Chains[chainId] = endpoint
+			return nil
+		})
+	}
+	return err
+}
+
+// Validate checks the config without reading the keystores
+func (c *Config) Validate() error {
+	if c.Endpoint.Address == "" && len(c.Chains) == 0 {
+		return errors.New("config: no endpoint")
+	}
+	if c.ChainId != "" {
+		if _, err := strconv.ParseUint(c.ChainId.String(), 10, 32); err != nil {
+			return fmt.Errorf("config: invalid chainId %s", c.ChainId)
+		}
+	}
+	for chainId, endpoint := range c.Chains {
+		if _, err := strconv.ParseUint(chainId, 10, 32); err != nil {
+			return fmt.Errorf("config: invalid chain id %q of endpoint", chainId)
+		}
+		if endpoint.Address == "" {
+			return fmt.Errorf("config: no address of the endpoint of chain %s", chainId)
+		}
+	}
+	if c.Network.BaseChainId < 0 {
+		return fmt.Errorf("config: invalid baseChainId %d", c.Network.BaseChainId)
+	}
+	if c.Network.SystemContracts != nil {
+		contracts := c.Network.SystemContracts
+		for _, address := range []string{contracts.Withdraw, contracts.Deposit, contracts.Cancel} {
+			if !common.IsStrictAddress(address) {
+				return fmt.Errorf("config: invalid system contract address %q", address)
+			}
+		}
+	}
+	if c.Timeout < 0 || c.Retry.MaxRetries < 0 || c.Retry.Backoff < 0 || c.Retry.MaxBackoff < 0 {
+		return errors.New("config: negative timeout or retry")
+	}
+	signers := c.ExtraSigners
+	if c.Signer != nil {
+		signers = append([]SignerConfig{*c.Signer}, signers...)
+	}
+	for _, signer := range signers {
+		if signer.Keystore == "" {
+			return errors.New("config: no keystore of signer")
+		}
+	}
+	return nil
+}
+
+// Provider builds the provider of the endpoints: an HTTPProvider with the rate limit, retries and
+// cache of each endpoint, routed by the chain ids of the requests
+func (c *Config) Provider() (providers.ProviderInterface, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	var defaultProvider providers.ProviderInterface
+	if c.Endpoint.Address != "" {
+		p, err := c.endpointProvider(c.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		if len(c.Chains) == 0 {
+			return p, nil
+		}
+		defaultProvider = p
+	}
+	chains := make(map[string]providers.ProviderInterface, len(c.Chains))
+	for chainId, endpoint := range c.Chains {
+		p, err := c.endpointProvider(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		chains[chainId] = p
+	}
+	return providers.NewChainRouter(defaultProvider, chains), nil
+}
+
+func (c *Config) endpointProvider(endpoint EndpointConfig) (providers.ProviderInterface, error) {
+	address, secure := endpoint.Address, endpoint.Secure
+	if strings.HasPrefix(address, "https://") {
+		address, secure = strings.TrimPrefix(address, "https://"), true
+	} else if strings.HasPrefix(address, "http://") {
+		address, secure = strings.TrimPrefix(address, "http://"), false
+	}
+	address = strings.TrimRight(address, "/")
+	timeout := time.Duration(endpoint.Timeout)
+	if timeout == 0 {
+		timeout = time.Duration(c.Timeout)
+	}
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	// the timeout of HTTPProvider is in seconds
+	seconds := int32((timeout + time.Second - 1) / time.Second)
+	var provider providers.ProviderInterface = providers.NewHTTPProvider(address, seconds, secure)
+	if endpoint.RateLimit > 0 || endpoint.MaxInFlight > 0 {
+		provider = providers.NewRateLimitedProvider(provider, providers.RateLimitOptions{
+			Endpoint:    providers.RateLimit{Rate: endpoint.RateLimit, Burst: endpoint.Burst},
+			MaxInFlight: endpoint.MaxInFlight,
+		})
+	}
+	if c.Retry.MaxRetries > 0 {
+		provider = providers.NewRetryProvider(provider, providers.RetryOptions{
+			MaxRetries: c.Retry.MaxRetries,
+			Backoff:    time.Duration(c.Retry.Backoff),
+			MaxBackoff: time.Duration(c.Retry.MaxBackoff),
+		})
+	}
+	if endpoint.Cache != nil {
+		head := providers.NewNodeHead(provider, 5*time.Second)
+		cache, err := providers.NewCachingProvider(provider, providers.CacheOptions{
+			Size:  endpoint.Cache.Size,
+			Rules: providers.DefaultCacheRules(head, endpoint.Cache.Confirmations),
+			Dir:   endpoint.Cache.Dir,
+		})
+		if err != nil {
+			return nil, err
+		}
+		provider = cache
+	}
+	return provider, nil
+}
+
+// Key reads the key from the keystore with the password of the signer
+func (s *SignerConfig) Key() (*keystore.Key, error) {
+	var password string
+	switch {
+	case s.PasswordFile != "":
+		b, err := ioutil.ReadFile(s.PasswordFile)
+		if err != nil {
+			return nil, err
+		}
+		password = strings.TrimRight(string(b), "\r\n")
+	default:
+		name := s.PasswordEnv
+		if name == "" {
+			name = DefaultPasswordEnv
+		}
+		var ok bool
+		if password, ok = os.LookupEnv(name); !ok {
+			return nil, fmt.Errorf("config: no password of keystore %s in %s", s.Keystore, name)
+		}
+	}
+	key, err := keystore.ReadKeyFile(s.Keystore, password)
+	if err != nil {
+		return nil, err
+	}
+	if s.Address != "" && common.HexToAddress(s.Address) != common.HexToAddress(key.Address) {
+		return nil, fmt.Errorf("config: keystore %s is of address %s, not %s", s.Keystore, key.Address, s.Address)
+	}
+	return key, nil
+}
+
+// NewWeb3FromConfig builds the provider by the config, and sets the Network and the defaults of Thk
+// with the keys of the signers
+func NewWeb3FromConfig(config *Config) (*Web3, error) {
+	provider, err := config.Provider()
+	if err != nil {
+		return nil, err
+	}
+	web3 := NewWeb3(provider)
+	web3.Thk.Network = config.Network.Network()
+	web3.Thk.DefaultChainId = config.ChainId.String()
+	if config.Signer != nil {
+		key, err := config.Signer.Key()
+		if err != nil {
+			return nil, err
+		}
+		web3.Thk.DefaultAddress = key.Address
+		web3.Thk.DefaultPrivateKey = key.PrivateKey
+	}
+	for i := range config.ExtraSigners {
+		key, err := config.ExtraSigners[i].Key()
+		if err != nil {
+			return nil, err
+		}
+		web3.Thk.DefaultExtraPrivateKeys = append(web3.Thk.DefaultExtraPrivateKeys, key.PrivateKey)
+	}
+	return web3, nil
+}
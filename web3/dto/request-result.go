@@ -2,6 +2,7 @@ package dto
 
 import (
 	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -18,11 +19,11 @@ import (
 )
 
 type RequestResult struct {
-	// ID      int         `json:"id"`
-	// Version string      `json:"jsonrpc"`
-	Result interface{} `json:"result"`
-	Error  *Error      `json:"error,omitempty"`
-	Data   string      `json:"data,omitempty"`
+	ID      int         `json:"id,omitempty"`
+	Version string      `json:"jsonrpc,omitempty"`
+	Result  interface{} `json:"result"`
+	Error   *Error      `json:"error,omitempty"`
+	Data    string      `json:"data,omitempty"`
 }
 
 type SendTxResult struct {
@@ -41,8 +42,15 @@ type MakeCCCExistenceProofJson struct {
 
 //GetCCCRelativeTx
 type GetCCCRelativeTxJson struct {
-	Proof  map[string]interface{} `json:"proof,omitempty"`
-	ErrMsg string                 `json:"ErrMsg,omitempty"`
+	Proof  *ChequeProof `json:"proof,omitempty"`
+	ErrMsg string       `json:"ErrMsg,omitempty"`
+}
+
+// ChequeProof is the proof generated by the node, which is the input of the transaction to cash or cancel a cheque
+type ChequeProof struct {
+	Input   string `json:"input"`
+	ErrCode int    `json:"errCode,omitempty"`
+	ErrMsg  string `json:"errMsg,omitempty"`
 }
 type CompileContractJson struct {
 	Test   map[string]interface{} `json:"test,omitempty"`
@@ -128,14 +136,14 @@ type BlockTxs struct {
 }
 
 type GetTransactions struct {
-	ChainId   int    `json:"chainId"`
-	From      string `json:"from"`
-	To        string `json:"to"`
-	Nonce     int    `json:"nonce"`
-	Value     int    `json:"value"`
-	Input     string `json:"input"`
-	Hash      string `json:"hash"`
-	Timestamp int64  `json:"timestamp"`
+	ChainId   int      `json:"chainId"`
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+	Nonce     int      `json:"nonce"`
+	Value     *big.Int `json:"value"`
+	Input     string   `json:"input"`
+	Hash      string   `json:"hash"`
+	Timestamp int64    `json:"timestamp"`
 }
 
 type GetChainStats struct {
@@ -173,6 +181,33 @@ type TxProof struct {
 	Error  string      `json:"errorMsg"`
 	ErrMsg string      `json:"ErrMsg,omitempty"`
 }
+// AccountProof is the account at a height with the proofs to the state root of the block
+type AccountProof struct {
+	Account *AccountState   `json:"account"`
+	Height  common.Height   `json:"height"`
+	Proof   trie.ProofChain `json:"proof"`             // folds the hash of the account to the StateRoot
+	Storage []*StorageProof `json:"storage,omitempty"` // proofs of the requested storage slots
+	ErrMsg  string          `json:"ErrMsg,omitempty"`
+}
+
+// AccountState is the account saved in the state trie, fields are in the order of the chain's encoding
+type AccountState struct {
+	Addr            common.Address `json:"address"`
+	Nonce           uint64         `json:"nonce"`
+	Balance         *big.Int       `json:"balance"`
+	LocalCurrency   *big.Int       `json:"localCurrency"`
+	StorageRoot     []byte         `json:"storageRoot"`
+	CodeHash        []byte         `json:"codeHash"`
+	LongStorageRoot []byte         `json:"longStorageRoot"`
+}
+
+// StorageProof is a storage slot of the contract with the proof to its StorageRoot
+type StorageProof struct {
+	Key   hexutil.Bytes   `json:"key"`
+	Value hexutil.Bytes   `json:"value"`
+	Proof trie.ProofChain `json:"proof"` // folds the value to the StorageRoot of the account
+}
+
 type MerkleItem struct {
 	HashVal   hexutil.Bytes `json:"hash"`
 	Direction uint8         `json:"direction"`
@@ -431,6 +466,73 @@ func (h *BlockHeader) HashValue() ([]byte, error) {
 	return ret, err
 }
 
+// HashValue returns the merkle root of the hashes of the members, which is the ElectedNextRoot
+// in the header of the block electing the committee
+func (c *Committee) HashValue() ([]byte, error) {
+	hashList := make([][]byte, 0, len(c.Members))
+	for _, id := range c.Members {
+		h, err := common.Hash256s(id[:])
+		if err != nil {
+			return nil, err
+		}
+		hashList = append(hashList, h)
+	}
+	return common.MerkleHashComplete(hashList, 0, nil)
+}
+
+var ErrHeaderMismatch = errors.New("block header mismatch")
+
+// HeaderMismatchError is returned when the header reported by the node is not consistent with the
+// full header, such as the hash reported is not the hash computed locally
+type HeaderMismatchError struct {
+	ChainId  common.ChainID
+	Height   common.Height
+	Field    string
+	Reported string
+	Computed string
+}
+
+func (e *HeaderMismatchError) Error() string {
+	return fmt.Sprintf("block header {%d %d} mismatch: %s reported %s, computed %s", e.ChainId, e.Height, e.Field, e.Reported, e.Computed)
+}
+
+func (e *HeaderMismatchError) Is(target error) bool {
+	return target == ErrHeaderMismatch
+}
+
+// VerifyHash checks the hash reported by the node against HashValue of the header
+func (h *BlockHeader) VerifyHash(reported string) error {
+	computed, err := h.HashValue()
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(strings.TrimPrefix(reported, "0x"), hex.EncodeToString(computed)) {
+		return &HeaderMismatchError{ChainId: h.ChainID, Height: h.Height, Field: "hash",
+			Reported: reported, Computed: hexutil.Encode(computed)}
+	}
+	return nil
+}
+
+// Verify checks that the result of GetBlockHeader is the summary of the full header
+func (r *GetBlockResult) Verify(h *BlockHeader) error {
+	mismatch := func(field, reported, computed string) error {
+		return &HeaderMismatchError{ChainId: h.ChainID, Height: h.Height, Field: field, Reported: reported, Computed: computed}
+	}
+	if r.ChainId != int(h.ChainID) {
+		return mismatch("chainid", strconv.Itoa(r.ChainId), strconv.Itoa(int(h.ChainID)))
+	}
+	if r.Height != int(h.Height) {
+		return mismatch("height", strconv.Itoa(r.Height), strconv.Itoa(int(h.Height)))
+	}
+	if r.Previoushash != "" && !strings.EqualFold(r.Previoushash, hexutil.Encode(h.PreviousHash[:])) {
+		return mismatch("previoushash", r.Previoushash, hexutil.Encode(h.PreviousHash[:]))
+	}
+	if r.Stateroot != "" && !strings.EqualFold(r.Stateroot, hexutil.Encode(h.StateRoot[:])) {
+		return mismatch("stateroot", r.Stateroot, hexutil.Encode(h.StateRoot[:]))
+	}
+	return h.VerifyHash(r.Hash)
+}
+
 // Hash value and its corresponding position are generated together to generate hash, which can
 // prove that this value is the value in this position
 func hashIndexProperty(posBuffer [13]byte, index byte, h []byte) []byte {
@@ -0,0 +1,231 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ThinkiumGroup/web3.go/common"
+	"github.com/ThinkiumGroup/web3.go/common/hexutil"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"io/ioutil"
+)
+
+const (
+	version = 3
+
+	// StandardScryptN and StandardScryptP are the scrypt parameters of the keystores of the wallets
+	StandardScryptN = 1 << 18
+	StandardScryptP = 1
+
+	// LightScryptN and LightScryptP use 4MB memory and about 100ms
+	LightScryptN = 1 << 12
+	LightScryptP = 6
+
+	scryptR     = 8
+	scryptDKLen = 32
+)
+
+var ErrDecrypt = errors.New("keystore: could not decrypt key with given password")
+
+// Key is the private key decrypted from a keystore, in the hex strings used by Thk
+type Key struct {
+	Address    string
+	PrivateKey string
+}
+
+type cryptoJSON struct {
+	Cipher       string                 `json:"cipher"`
+	CipherText   string                 `json:"ciphertext"`
+	CipherParams cipherParamsJSON       `json:"cipherparams"`
+	KDF          string                 `json:"kdf"`
+	KDFParams    map[string]interface{} `json:"kdfparams"`
+	MAC          string                 `json:"mac"`
+}
+
+type cipherParamsJSON struct {
+	IV string `json:"iv"`
+}
+
+// keyJSON is the version 3 keystore file
+type keyJSON struct {
+	Address string     `json:"address"`
+	Crypto  cryptoJSON `json:"crypto"`
+	Id      string     `json:"id"`
+	Version int        `json:"version"`
+}
+
+// ReadKeyFile reads and decrypts the keystore file
+func ReadKeyFile(path, password string) (*Key, error) {
+	keyjson, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := DecryptKey(keyjson, password)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+// DecryptKey decrypts the version 3 keystore with the scrypt or pbkdf2 kdf, and checks the address
+// of the key if it's in the keystore
+func DecryptKey(keyjson []byte, password string) (*Key, error) {
+	var k keyJSON
+	if err := json.Unmarshal(keyjson, &k); err != nil {
+		return nil, err
+	}
+	if k.Version != version {
+		return nil, fmt.Errorf("keystore: unsupported version %d", k.Version)
+	}
+	c := k.Crypto
+	if c.Cipher != "aes-128-ctr" {
+		return nil, fmt.Errorf("keystore: unsupported cipher %q", c.Cipher)
+	}
+	mac, err := hex.DecodeString(c.MAC)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := hex.DecodeString(c.CipherParams.IV)
+	if err != nil {
+		return nil, err
+	}
+	cipherText, err := hex.DecodeString(c.CipherText)
+	if err != nil {
+		return nil, err
+	}
+	derivedKey, err := deriveKey(c, password)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(common.SystemHash256(derivedKey[16:32], cipherText), mac) {
+		return nil, ErrDecrypt
+	}
+	priv, err := aesCTR(derivedKey[:16], cipherText, iv)
+	if err != nil {
+		return nil, err
+	}
+	key, err := newKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	if k.Address != "" && common.HexToAddress(k.Address) != common.HexToAddress(key.Address) {
+		return nil, fmt.Errorf("keystore: key of address %s, not %s", key.Address, k.Address)
+	}
+	return key, nil
+}
+
+// EncryptKey encrypts the private key in hex into a version 3 keystore with the scrypt kdf
+func EncryptKey(privateKey, password string, scryptN, scryptP int) ([]byte, error) {
+	priv, err := hexutil.Decode(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	key, err := newKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	salt, iv, id := make([]byte, 32), make([]byte, aes.BlockSize), make([]byte, 16)
+	for _, b := range [][]byte{salt, iv, id} {
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+	}
+	derivedKey, err := scrypt.Key([]byte(password), salt, scryptN, scryptR, scryptP, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+	cipherText, err := aesCTR(derivedKey[:16], priv, iv)
+	if err != nil {
+		return nil, err
+	}
+	// random uuid of version 4
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return json.Marshal(keyJSON{
+		Address: common.CleanHexPrefix(key.Address),
+		Crypto: cryptoJSON{
+			Cipher:       "aes-128-ctr",
+			CipherText:   hex.EncodeToString(cipherText),
+			CipherParams: cipherParamsJSON{IV: hex.EncodeToString(iv)},
+			KDF:          "scrypt",
+			KDFParams: map[string]interface{}{
+				"n":     scryptN,
+				"r":     scryptR,
+				"p":     scryptP,
+				"dklen": scryptDKLen,
+				"salt":  hex.EncodeToString(salt),
+			},
+			MAC: hex.EncodeToString(common.SystemHash256(derivedKey[16:32], cipherText)),
+		},
+		Id:      fmt.Sprintf("%x-%x-%x-%x-%x", id[:4], id[4:6], id[6:8], id[8:10], id[10:]),
+		Version: version,
+	})
+}
+
+func newKey(priv []byte) (*Key, error) {
+	key, err := common.Cipher.BytesToPriv(priv)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{
+		Address:    hexutil.Encode(key.GetPublicKey().ToAddress()),
+		PrivateKey: hexutil.Encode(priv),
+	}, nil
+}
+
+func deriveKey(c cryptoJSON, password string) ([]byte, error) {
+	salt, err := hex.DecodeString(stringParam(c.KDFParams, "salt"))
+	if err != nil {
+		return nil, err
+	}
+	dkLen := intParam(c.KDFParams, "dklen")
+	if dkLen < 32 {
+		return nil, fmt.Errorf("keystore: dklen %d is too short", dkLen)
+	}
+	switch c.KDF {
+	case "scrypt":
+		return scrypt.Key([]byte(password), salt, intParam(c.KDFParams, "n"), intParam(c.KDFParams, "r"),
+			intParam(c.KDFParams, "p"), dkLen)
+	case "pbkdf2":
+		if prf := stringParam(c.KDFParams, "prf"); prf != "hmac-sha256" {
+			return nil, fmt.Errorf("keystore: unsupported pbkdf2 prf %q", prf)
+		}
+		iter := intParam(c.KDFParams, "c")
+		if iter <= 0 {
+			return nil, errors.New("keystore: missing pbkdf2 iterations")
+		}
+		return pbkdf2.Key([]byte(password), salt, iter, dkLen, sha256.New), nil
+	default:
+		return nil, fmt.Errorf("keystore: unsupported kdf %q", c.KDF)
+	}
+}
+
+func aesCTR(key, in, iv []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("keystore: iv of %d bytes", len(iv))
+	}
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+	return out, nil
+}
+
+func intParam(params map[string]interface{}, name string) int {
+	f, _ := params[name].(float64)
+	return int(f)
+}
+
+func stringParam(params map[string]interface{}, name string) string {
+	s, _ := params[name].(string)
+	return s
+}
@@ -0,0 +1,268 @@
+package lightclient
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/ThinkiumGroup/go-common"
+	"github.com/ThinkiumGroup/web3.go/web3/dto"
+	"github.com/ThinkiumGroup/web3.go/web3/thk"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrUntrustedEpoch   = errors.New("epoch is before the trusted checkpoint")
+	ErrUnknownCommittee = errors.New("committee of the epoch is unknown")
+	ErrNoQuorum         = errors.New("not enough committee signatures")
+	ErrCommitteeRoot    = errors.New("next committee does not match the ElectedNextRoot")
+)
+
+// Checkpoint is the committee of an epoch trusted by the user, such as from a block explorer or
+// a config file. Committees of the following epochs are derived from the verified blocks.
+type Checkpoint struct {
+	Epoch   common.EpochNum `json:"epoch"`
+	Members []string        `json:"members"` // node ids in hex
+}
+
+// LightClient verifies blocks by the signatures of the committee, and tracks the committee
+// across epochs by the NextCommittee/NextRealCommittee in the last block of each epoch, which
+// must match the ElectedNextRoot signed in the header of the block.
+type LightClient struct {
+	ChainId     string
+	EpochLength uint64 // blocks in an epoch, from GetStats by default
+	// AllowNodeCommittee uses GetCommittee of the node when the next committee is not in the last
+	// block of an epoch. It makes the verification trusting the node, so it's false by default.
+	AllowNodeCommittee bool
+
+	thk        *thk.Thk
+	lock       sync.Mutex
+	checkpoint common.EpochNum
+	latest     common.EpochNum
+	committees map[common.EpochNum][]common.NodeID
+}
+
+// New creates the light client of the chain starting from the trusted checkpoint
+func New(t *thk.Thk, chainId string, checkpoint Checkpoint) (*LightClient, error) {
+	members, err := parseNodeIds(checkpoint.Members)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, errors.New("empty committee in checkpoint")
+	}
+	stats, err := t.GetStats(chainId)
+	if err != nil {
+		return nil, err
+	}
+	if stats.EpochLength <= 0 {
+		return nil, fmt.Errorf("invalid epoch length %d of chain %s", stats.EpochLength, chainId)
+	}
+	return &LightClient{
+		ChainId:     chainId,
+		EpochLength: uint64(stats.EpochLength),
+		thk:         t,
+		checkpoint:  checkpoint.Epoch,
+		latest:      checkpoint.Epoch,
+		committees:  map[common.EpochNum][]common.NodeID{checkpoint.Epoch: members},
+	}, nil
+}
+
+// CheckpointFromNode gets the committee of the epoch by GetCommittee. The checkpoint is trusting
+// the node, which should be the one run by the user.
+func CheckpointFromNode(t *thk.Thk, chainId string, epoch common.EpochNum) (Checkpoint, error) {
+	members, err := t.GetCommittee(chainId, strconv.FormatUint(uint64(epoch), 10))
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	return Checkpoint{Epoch: epoch, Members: members}, nil
+}
+
+// Epoch returns the epoch of the height
+func (lc *LightClient) Epoch(height common.Height) common.EpochNum {
+	return common.EpochNum(uint64(height) / lc.EpochLength)
+}
+
+// Committee returns the committee of the epoch, the blocks at the end of the epochs between the
+// latest known one and the epoch are fetched and verified to derive it.
+func (lc *LightClient) Committee(epoch common.EpochNum) ([]common.NodeID, error) {
+	lc.lock.Lock()
+	defer lc.lock.Unlock()
+	if epoch < lc.checkpoint {
+		return nil, fmt.Errorf("%w: %d < %d", ErrUntrustedEpoch, epoch, lc.checkpoint)
+	}
+	for lc.latest < epoch {
+		if err := lc.advance(); err != nil {
+			return nil, err
+		}
+	}
+	return lc.committees[epoch], nil
+}
+
+// advance derives the committee of the epoch after the latest one
+func (lc *LightClient) advance() error {
+	next := lc.latest + 1
+	lastHeight := common.Height(uint64(next)*lc.EpochLength - 1)
+	block, err := lc.thk.GetBlock(lc.ChainId, strconv.FormatUint(uint64(lastHeight), 10))
+	if err != nil {
+		return err
+	}
+	if _, err = lc.verify(block, lastHeight, lc.committees[lc.latest]); err != nil {
+		return err
+	}
+	members := nextCommittee(block.BlockBody)
+	if len(members) > 0 {
+		if err = checkCommitteeRoot(block.BlockHeader, members, true); err != nil {
+			return err
+		}
+	} else {
+		if !lc.AllowNodeCommittee {
+			return fmt.Errorf("%w: %d, no next committee in block %d", ErrUnknownCommittee, next, lastHeight)
+		}
+		ids, err := lc.thk.GetCommittee(lc.ChainId, strconv.FormatUint(uint64(next), 10))
+		if err != nil {
+			return err
+		}
+		if members, err = parseNodeIds(ids); err != nil {
+			return err
+		}
+		if len(members) == 0 {
+			return fmt.Errorf("%w: %d", ErrUnknownCommittee, next)
+		}
+		if err = checkCommitteeRoot(block.BlockHeader, members, false); err != nil {
+			return err
+		}
+	}
+	lc.committees[next] = members
+	lc.latest = next
+	return nil
+}
+
+// nextCommittee returns NextRealCommittee if the election failed and the current committee continues,
+// or the elected NextCommittee
+func nextCommittee(body *dto.BlockBody) []common.NodeID {
+	if body == nil {
+		return nil
+	}
+	if body.NextRealCommittee != nil && len(body.NextRealCommittee.Members) > 0 {
+		return body.NextRealCommittee.Members
+	}
+	if body.NextCommittee != nil {
+		return body.NextCommittee.Members
+	}
+	return nil
+}
+
+// checkCommitteeRoot checks the members against the ElectedNextRoot of the verified header, which
+// can be missing only if the root is not required
+func checkCommitteeRoot(header *dto.BlockHeader, members []common.NodeID, required bool) error {
+	if header.ElectedNextRoot == nil {
+		if required {
+			return fmt.Errorf("%w: no ElectedNextRoot in block %d", ErrCommitteeRoot, header.Height)
+		}
+		return nil
+	}
+	root, err := (&dto.Committee{Members: members}).HashValue()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(root, header.ElectedNextRoot[:]) {
+		return fmt.Errorf("%w: %x in block %d, but %x", ErrCommitteeRoot, header.ElectedNextRoot[:], header.Height, root)
+	}
+	return nil
+}
+
+// VerifyBlock checks that at least 2/3 of the committee of the block's epoch signed the block,
+// and returns the count of valid committee signatures
+func (lc *LightClient) VerifyBlock(block *dto.BlockDetail) (int, error) {
+	if block == nil || block.BlockHeader == nil {
+		return 0, errors.New("no block header")
+	}
+	committee, err := lc.Committee(lc.Epoch(block.BlockHeader.Height))
+	if err != nil {
+		return 0, err
+	}
+	return lc.verify(block, block.BlockHeader.Height, committee)
+}
+
+// VerifiedGetBlock fetches the block and returns it only if it passes VerifyBlock
+func (lc *LightClient) VerifiedGetBlock(height common.Height) (*dto.BlockDetail, error) {
+	block, err := lc.thk.GetBlock(lc.ChainId, strconv.FormatUint(uint64(height), 10))
+	if err != nil {
+		return nil, err
+	}
+	if block.BlockHeader == nil || block.BlockHeader.Height != height {
+		return nil, fmt.Errorf("block %d is not returned", height)
+	}
+	if _, err = lc.VerifyBlock(block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (lc *LightClient) verify(block *dto.BlockDetail, height common.Height, committee []common.NodeID) (int, error) {
+	header := block.BlockHeader
+	if header == nil {
+		return 0, fmt.Errorf("no header of block %d", height)
+	}
+	if header.Height != height || strconv.FormatUint(uint64(header.ChainID), 10) != lc.ChainId {
+		return 0, fmt.Errorf("block {%d %d} is returned for {%s %d}", header.ChainID, header.Height, lc.ChainId, height)
+	}
+	return CountCommitteeSignatures(header, block.BlockPass, committee)
+}
+
+// CountCommitteeSignatures counts the valid signatures of the distinct committee members, and
+// returns ErrNoQuorum if they are less than 2/3 of the committee
+func CountCommitteeSignatures(header *dto.BlockHeader, pass dto.PubAndSigs, committee []common.NodeID) (int, error) {
+	if len(committee) == 0 {
+		return 0, ErrUnknownCommittee
+	}
+	members := make(map[common.NodeID]bool, len(committee))
+	for _, id := range committee {
+		members[id] = false
+	}
+	count := 0
+	for _, pas := range pass {
+		if pas == nil {
+			continue
+		}
+		id := pubToNodeId(pas.PublicKey[:])
+		signed, ok := members[id]
+		if !ok || signed {
+			continue
+		}
+		if !common.VerifyMsg(header, pas.PublicKey.Bytes(), pas.Signature.Bytes()) {
+			continue
+		}
+		members[id] = true
+		count++
+	}
+	if count*3 < len(committee)*2 {
+		return count, fmt.Errorf("%w: %d of %d at height %d", ErrNoQuorum, count, len(committee), header.Height)
+	}
+	return count, nil
+}
+
+// pubToNodeId drops the 0x04 prefix of the uncompressed public key
+func pubToNodeId(pub []byte) common.NodeID {
+	var id common.NodeID
+	if len(pub) == len(id)+1 {
+		pub = pub[1:]
+	}
+	copy(id[:], pub)
+	return id
+}
+
+func parseNodeIds(ids []string) ([]common.NodeID, error) {
+	nodeIds := make([]common.NodeID, 0, len(ids))
+	for _, id := range ids {
+		b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(id, "0x"), "0X"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid node id %s: %v", id, err)
+		}
+		nodeIds = append(nodeIds, pubToNodeId(b))
+	}
+	return nodeIds, nil
+}
@@ -0,0 +1,319 @@
+package providers
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/ThinkiumGroup/web3.go/web3/providers/util"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CacheRule decides whether the successful result of a request never changes and can be cached
+type CacheRule func(params interface{}, result json.RawMessage) bool
+
+// Immutable caches all successful results, such as transactions by hash
+func Immutable(params interface{}, result json.RawMessage) bool {
+	return true
+}
+
+// HeadFunc returns the current head of the chain, such as the height or the epoch
+type HeadFunc func(chainId string) (uint64, error)
+
+// ParamBelowHead caches the results if the param, such as "height", is at least margin below the
+// head of the chain in the "chainId" param
+func ParamBelowHead(param string, head HeadFunc, margin uint64) CacheRule {
+	return func(params interface{}, result json.RawMessage) bool {
+		values := paramsOf(params)
+		value, err := strconv.ParseUint(values[param], 10, 64)
+		if err != nil {
+			return false
+		}
+		current, err := head(values["chainId"])
+		if err != nil {
+			return false
+		}
+		return value+margin <= current
+	}
+}
+
+// NodeHead gets the heads of the chains by GetStats, which are cached for TTL
+type NodeHead struct {
+	TTL time.Duration
+
+	provider ProviderInterface
+	lock     sync.Mutex
+	stats    map[string]nodeStats
+}
+
+type nodeStats struct {
+	height      uint64
+	epochLength uint64
+	at          time.Time
+}
+
+func NewNodeHead(provider ProviderInterface, ttl time.Duration) *NodeHead {
+	return &NodeHead{TTL: ttl, provider: provider, stats: make(map[string]nodeStats)}
+}
+
+func (h *NodeHead) get(chainId string) (nodeStats, error) {
+	h.lock.Lock()
+	s, ok := h.stats[chainId]
+	h.lock.Unlock()
+	if ok && time.Since(s.at) < h.TTL {
+		return s, nil
+	}
+	var res struct {
+		CurrentHeight uint64 `json:"currentheight"`
+		EpochLength   uint64 `json:"epochlength"`
+	}
+	if err := h.provider.SendRequest(&res, "GetStats", map[string]string{"chainId": chainId}); err != nil {
+		return nodeStats{}, err
+	}
+	s = nodeStats{height: res.CurrentHeight, epochLength: res.EpochLength, at: time.Now()}
+	h.lock.Lock()
+	h.stats[chainId] = s
+	h.lock.Unlock()
+	return s, nil
+}
+
+// Height is the HeadFunc of the current height
+func (h *NodeHead) Height(chainId string) (uint64, error) {
+	s, err := h.get(chainId)
+	return s.height, err
+}
+
+// Epoch is the HeadFunc of the current epoch
+func (h *NodeHead) Epoch(chainId string) (uint64, error) {
+	s, err := h.get(chainId)
+	if err != nil || s.epochLength == 0 {
+		return 0, err
+	}
+	return s.height / s.epochLength, nil
+}
+
+// DefaultCacheRules caches the blocks at least confirmations below the head, the transactions and
+// proofs by hash, and the committees of the past epochs
+func DefaultCacheRules(head *NodeHead, confirmations uint64) map[string]CacheRule {
+	belowHead := ParamBelowHead("height", head.Height, confirmations)
+	return map[string]CacheRule{
+		"GetBlock":                belowHead,
+		"GetBlockHeader":          belowHead,
+		"GetBlockTxs":             belowHead,
+		"GetTransactionByHash":    Immutable,
+		"GetTxProof":              Immutable,
+		"/chaininfo:GetCommittee": ParamBelowHead("epoch", head.Epoch, 1),
+	}
+}
+
+type CacheOptions struct {
+	Size  int                  // entries in memory, 1024 by default
+	Rules map[string]CacheRule // results of the other methods are not cached
+	Dir   string               // directory persisting the cached results, empty for memory only
+}
+
+type cacheEntry struct {
+	key   string
+	value json.RawMessage
+}
+
+type flight struct {
+	done  chan struct{}
+	value json.RawMessage
+	err   error
+}
+
+// CachingProvider caches the results of the methods with rules in an LRU, and optionally in files.
+// Concurrent identical requests of the methods are sent once.
+type CachingProvider struct {
+	provider ProviderInterface
+	opts     CacheOptions
+
+	lock    sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+	flights map[string]*flight
+	hits    uint64
+	misses  uint64
+}
+
+func NewCachingProvider(provider ProviderInterface, opts CacheOptions) (*CachingProvider, error) {
+	if opts.Size <= 0 {
+		opts.Size = 1024
+	}
+	if opts.Dir != "" {
+		if err := os.MkdirAll(opts.Dir, 0700); err != nil {
+			return nil, err
+		}
+	}
+	return &CachingProvider{
+		provider: provider,
+		opts:     opts,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+		flights:  make(map[string]*flight),
+	}, nil
+}
+
+// Stats returns the count of the requests answered by the cache, and the ones sent to the provider
+func (c *CachingProvider) Stats() (hits, misses uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.hits, c.misses
+}
+
+func (c *CachingProvider) SendRequest(v interface{}, method string, params interface{}) error {
+	rule, ok := c.opts.Rules[method]
+	if !ok {
+		return c.provider.SendRequest(v, method, params)
+	}
+	b, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(append([]byte(method+"\n"), b...))
+	key := hex.EncodeToString(sum[:])
+
+	c.lock.Lock()
+	if value, ok := c.getLocked(key); ok {
+		c.hits++
+		c.lock.Unlock()
+		return json.Unmarshal(value, v)
+	}
+	if f, ok := c.flights[key]; ok {
+		c.hits++
+		c.lock.Unlock()
+		<-f.done
+		if f.err != nil {
+			return f.err
+		}
+		return json.Unmarshal(f.value, v)
+	}
+	f := &flight{done: make(chan struct{})}
+	c.flights[key] = f
+	c.lock.Unlock()
+
+	// the file is read out of the lock, and the identical requests wait for the flight meanwhile
+	value, cached := c.load(key)
+	c.lock.Lock()
+	if cached {
+		c.hits++
+		c.putLocked(key, value)
+		f.value = value
+	} else {
+		c.misses++
+	}
+	c.lock.Unlock()
+	if !cached {
+		f.err = c.provider.SendRequest(&f.value, method, params)
+		if f.err == nil && !isErrorResult(f.value) && rule(params, f.value) {
+			c.lock.Lock()
+			c.putLocked(key, f.value)
+			c.lock.Unlock()
+			c.persist(key, f.value)
+		}
+	}
+	c.lock.Lock()
+	delete(c.flights, key)
+	c.lock.Unlock()
+	close(f.done)
+	if f.err != nil {
+		return f.err
+	}
+	return json.Unmarshal(f.value, v)
+}
+
+func (c *CachingProvider) Close() error {
+	return c.provider.Close()
+}
+
+func (c *CachingProvider) getLocked(key string) (json.RawMessage, bool) {
+	if e, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(e)
+		return e.Value.(*cacheEntry).value, true
+	}
+	return nil, false
+}
+
+// load reads the result persisted in the file
+func (c *CachingProvider) load(key string) (json.RawMessage, bool) {
+	if c.opts.Dir == "" {
+		return nil, false
+	}
+	value, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+func (c *CachingProvider) putLocked(key string, value json.RawMessage) {
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*cacheEntry).value = value
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, value: value})
+	for c.lru.Len() > c.opts.Size {
+		last := c.lru.Back()
+		c.lru.Remove(last)
+		delete(c.entries, last.Value.(*cacheEntry).key)
+	}
+}
+
+// persist writes the result into a file, errors are ignored since the file is only a cache
+func (c *CachingProvider) persist(key string, value json.RawMessage) {
+	if c.opts.Dir == "" {
+		return
+	}
+	tmp := c.path(key) + ".tmp"
+	if err := ioutil.WriteFile(tmp, value, 0600); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, c.path(key)); err != nil {
+		_ = os.Remove(tmp)
+	}
+}
+
+func (c *CachingProvider) path(key string) string {
+	return filepath.Join(c.opts.Dir, key+".json")
+}
+
+// isErrorResult checks the empty result and the error message of the result, which is never cached
+func isErrorResult(raw json.RawMessage) bool {
+	if len(raw) == 0 || string(raw) == "null" {
+		return true
+	}
+	msg, err := util.ResultErrMsg(raw)
+	return err != nil || msg != ""
+}
+
+// paramsOf returns the fields of the params in strings
+func paramsOf(params interface{}) map[string]string {
+	values := make(map[string]string)
+	b, err := json.Marshal(params)
+	if err != nil {
+		return values
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(b, &fields) != nil {
+		return values
+	}
+	for k, raw := range fields {
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			values[k] = s
+			continue
+		}
+		var n json.Number
+		if json.Unmarshal(raw, &n) == nil {
+			values[k] = n.String()
+		}
+	}
+	return values
+}
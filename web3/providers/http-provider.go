@@ -3,10 +3,12 @@ package providers
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"github.com/ThinkiumGroup/web3.go/web3/providers/util"
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -32,47 +34,158 @@ func newHTTPProviderWithClient(address string, timeout int32, secure bool, clien
 	return provider
 }
 
+// requestId is the id of the last request sent by the providers
+var requestId uint64
+
+func nextRequestId() uint64 {
+	return atomic.AddUint64(&requestId, 1)
+}
+
+// RPCError is the error object in the JSON-RPC 2.0 response
+type RPCError = util.JsonError
+
+// HTTPError is returned if the node responds an http error status without a JSON-RPC error
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("http status %d: %s", e.StatusCode, e.Body)
+}
+
+// SendRequest sends the JSON-RPC 2.0 request with a new id, and decodes the result into v. The id of
+// the response is checked if it's an envelope, otherwise it's the legacy flat result decoded directly.
+// A method with a path prefix, such as "/chaininfo:Ping", is sent to the path.
 func (provider HTTPProvider) SendRequest(v interface{}, method string, params interface{}) error {
+	path, method := splitMethod(method)
+	id := nextRequestId()
+	body, err := provider.post(path, util.JsonParam{Version: util.Version, ID: id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	return decodeResult(body, id, v)
+}
+
+// BatchElem is a request in a batch, Result is decoded as in SendRequest and Error is the error of it
+type BatchElem struct {
+	Method string
+	Params interface{}
+	Result interface{}
+	Error  error
+}
+
+// SendBatch sends the requests in one JSON-RPC 2.0 batch, the responses are matched by ids. All
+// methods must have the same path prefix. The returned error is of the batch, and errors of the
+// requests are set to their Error.
+func (provider HTTPProvider) SendBatch(batch []*BatchElem) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	path, _ := splitMethod(batch[0].Method)
+	requests := make([]util.JsonParam, len(batch))
+	ids := make(map[uint64]*BatchElem, len(batch))
+	for i, elem := range batch {
+		p, method := splitMethod(elem.Method)
+		if p != path {
+			return fmt.Errorf("method %s is not of path %q", elem.Method, path)
+		}
+		requests[i] = util.JsonParam{Version: util.Version, ID: nextRequestId(), Method: method, Params: elem.Params}
+		ids[requests[i].ID] = elem
+	}
+	body, err := provider.post(path, requests)
+	if err != nil {
+		return err
+	}
+	var responses []json.RawMessage
+	if err = json.Unmarshal(body, &responses); err != nil {
+		return err
+	}
+	for _, raw := range responses {
+		var res util.JsonResult
+		if err = json.Unmarshal(raw, &res); err != nil {
+			return err
+		}
+		for id, elem := range ids {
+			if res.HasID(id) {
+				elem.Error = decodeResult(raw, id, elem.Result)
+				delete(ids, id)
+				break
+			}
+		}
+	}
+	for id, elem := range ids {
+		elem.Error = fmt.Errorf("no response of request %d", id)
+	}
+	return nil
+}
+
+func splitMethod(method string) (path string, name string) {
 	arr := strings.Split(method, ":")
-	var path = ""
 	if len(arr) == 2 {
-		path = arr[0]
-		method = arr[1]
+		return arr[0], arr[1]
 	}
-	bodyString := util.JsonParam{Method: method, Params: params}
+	return "", method
+}
+
+func (provider HTTPProvider) post(path string, request interface{}) ([]byte, error) {
 	prefix := "http://"
 	if provider.secure {
 		prefix = "https://"
 	}
-	bufferParams, err := json.Marshal(bodyString)
-	url := prefix + provider.address
-	if path != "" {
-		url = url + path
+	bufferParams, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
 	}
+	url := prefix + provider.address + path
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(bufferParams))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Add("Accept", "application/json")
 
 	resp, err := provider.client.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	var bodyBytes []byte
-	if resp.StatusCode == 200 {
-		bodyBytes, err = ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return err
-		} else {
-			return json.Unmarshal(bodyBytes, v)
+	bodyBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		// a JSON-RPC error may be responded with an http error status
+		var res util.JsonResult
+		if json.Unmarshal(bodyBytes, &res) == nil && res.IsEnvelope() && res.Error != nil {
+			return bodyBytes, nil
 		}
+		return nil, &HTTPError{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(bodyBytes))}
 	}
+	return bodyBytes, nil
+}
 
-	return json.Unmarshal(bodyBytes, v)
+// decodeResult decodes the result of the envelope, or the legacy flat result, into v
+func decodeResult(body []byte, id uint64, v interface{}) error {
+	var res util.JsonResult
+	if err := json.Unmarshal(body, &res); err != nil || !res.IsEnvelope() {
+		// legacy response, or an array which is not an envelope
+		return json.Unmarshal(body, v)
+	}
+	if res.Version != util.Version {
+		return fmt.Errorf("unsupported jsonrpc version %q", res.Version)
+	}
+	if !res.HasID(id) {
+		return fmt.Errorf("response id %s does not match request id %d", res.ID, id)
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if len(res.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(res.Result, v)
 }
 
 func (provider HTTPProvider) Close() error {
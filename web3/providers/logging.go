@@ -0,0 +1,111 @@
+package providers
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redactedKeys are the lower-case keys of the params never logged
+var redactedKeys = map[string]bool{
+	"sig":        true,
+	"multisigs":  true,
+	"privatekey": true,
+	"privkey":    true,
+	"priv":       true,
+	"key":        true,
+	"authkey":    true,
+	"secret":     true,
+	"password":   true,
+	"passphrase": true,
+	"mnemonic":   true,
+	"seed":       true,
+}
+
+const redacted = "[REDACTED]"
+
+// LogEntry is a line written by LogHook
+type LogEntry struct {
+	Time       time.Time   `json:"time"`
+	Method     string      `json:"method"`
+	ChainId    string      `json:"chainId,omitempty"`
+	DurationMs float64     `json:"durationMs"`
+	Size       int         `json:"size"`
+	Error      string      `json:"error,omitempty"`
+	Params     interface{} `json:"params,omitempty"`
+}
+
+// LogHook writes a JSON line for each request. Signatures and private material in the params are
+// redacted, and the params are not logged unless LogParams.
+type LogHook struct {
+	LogParams bool
+	OnlyError bool // only the failed requests are logged
+
+	lock sync.Mutex
+	w    io.Writer
+}
+
+func NewLogHook(w io.Writer) *LogHook {
+	return &LogHook{w: w}
+}
+
+func (h *LogHook) Before(call *Call) {}
+
+func (h *LogHook) After(call *Call) {
+	if h.OnlyError && call.Err == nil {
+		return
+	}
+	entry := LogEntry{
+		Time:       call.Start,
+		Method:     call.Method,
+		ChainId:    call.ChainId,
+		DurationMs: float64(call.Duration.Microseconds()) / 1000,
+		Size:       call.ResponseSize,
+	}
+	if call.Err != nil {
+		entry.Error = call.Err.Error()
+	}
+	if h.LogParams {
+		entry.Params = RedactParams(call.Params)
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	_, _ = h.w.Write(append(b, '\n'))
+}
+
+// RedactParams returns the params in JSON values with the signatures and private material replaced
+func RedactParams(params interface{}) interface{} {
+	b, err := json.Marshal(params)
+	if err != nil {
+		return nil
+	}
+	var v interface{}
+	if err = json.Unmarshal(b, &v); err != nil {
+		return nil
+	}
+	return redact(v)
+}
+
+func redact(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, value := range x {
+			if redactedKeys[strings.ToLower(k)] {
+				x[k] = redacted
+			} else {
+				x[k] = redact(value)
+			}
+		}
+	case []interface{}:
+		for i := range x {
+			x[i] = redact(x[i])
+		}
+	}
+	return v
+}
@@ -0,0 +1,142 @@
+package providers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the upper bounds of the duration histogram in seconds
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// MethodStats is the statistics of a method at a chain
+type MethodStats struct {
+	Method        string
+	ChainId       string
+	Requests      uint64
+	Errors        uint64
+	ResponseBytes uint64
+	DurationSum   float64  // seconds
+	BucketCounts  []uint64 // requests not longer than the bucket, not cumulative
+}
+
+type seriesKey struct {
+	method  string
+	chainId string
+}
+
+// Metrics is a Hook collecting the counters and the duration histograms of the requests per method
+// and chain, which can be exposed in the Prometheus text format
+type Metrics struct {
+	Namespace string // prefix of the metric names, "thk" by default
+
+	buckets []float64
+	lock    sync.Mutex
+	series  map[seriesKey]*MethodStats
+}
+
+// NewMetrics creates the collector with the buckets in seconds, DefaultBuckets if not given
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	return &Metrics{Namespace: "thk", buckets: b, series: make(map[seriesKey]*MethodStats)}
+}
+
+func (m *Metrics) Before(call *Call) {}
+
+func (m *Metrics) After(call *Call) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	key := seriesKey{method: call.Method, chainId: call.ChainId}
+	stats, ok := m.series[key]
+	if !ok {
+		stats = &MethodStats{Method: call.Method, ChainId: call.ChainId, BucketCounts: make([]uint64, len(m.buckets))}
+		m.series[key] = stats
+	}
+	stats.Requests++
+	if call.Err != nil {
+		stats.Errors++
+	}
+	stats.ResponseBytes += uint64(call.ResponseSize)
+	seconds := call.Duration.Seconds()
+	stats.DurationSum += seconds
+	if i := sort.SearchFloat64s(m.buckets, seconds); i < len(m.buckets) {
+		stats.BucketCounts[i]++
+	}
+}
+
+// Snapshot returns the copies of the statistics ordered by method and chain
+func (m *Metrics) Snapshot() []MethodStats {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	all := make([]MethodStats, 0, len(m.series))
+	for _, stats := range m.series {
+		s := *stats
+		s.BucketCounts = append([]uint64(nil), stats.BucketCounts...)
+		all = append(all, s)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Method != all[j].Method {
+			return all[i].Method < all[j].Method
+		}
+		return all[i].ChainId < all[j].ChainId
+	})
+	return all
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	all := m.Snapshot()
+	ns := m.Namespace
+	if ns == "" {
+		ns = "thk"
+	}
+	bw := bufio.NewWriter(w)
+	counter := func(name, help string, value func(s *MethodStats) uint64) {
+		fmt.Fprintf(bw, "# HELP %s_%s %s\n# TYPE %s_%s counter\n", ns, name, help, ns, name)
+		for i := range all {
+			fmt.Fprintf(bw, "%s_%s{%s} %d\n", ns, name, labels(&all[i]), value(&all[i]))
+		}
+	}
+	counter("rpc_requests_total", "Requests sent to the node.", func(s *MethodStats) uint64 { return s.Requests })
+	counter("rpc_errors_total", "Requests failed.", func(s *MethodStats) uint64 { return s.Errors })
+	counter("rpc_response_bytes_total", "Bytes of the responses.", func(s *MethodStats) uint64 { return s.ResponseBytes })
+
+	name := ns + "_rpc_duration_seconds"
+	fmt.Fprintf(bw, "# HELP %s Duration of the requests.\n# TYPE %s histogram\n", name, name)
+	for i := range all {
+		s, l := &all[i], labels(&all[i])
+		var cumulative uint64
+		for j, bound := range m.buckets {
+			cumulative += s.BucketCounts[j]
+			fmt.Fprintf(bw, "%s_bucket{%s,le=\"%s\"} %d\n", name, l, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(bw, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, l, s.Requests)
+		fmt.Fprintf(bw, "%s_sum{%s} %s\n", name, l, strconv.FormatFloat(s.DurationSum, 'g', -1, 64))
+		fmt.Fprintf(bw, "%s_count{%s} %d\n", name, l, s.Requests)
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP serves the metrics for the Prometheus scraper
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_ = m.WritePrometheus(w)
+}
+
+func labels(s *MethodStats) string {
+	return fmt.Sprintf("method=%s,chain=%s", quoteLabel(s.Method), quoteLabel(s.ChainId))
+}
+
+func quoteLabel(v string) string {
+	v = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+	return `"` + v + `"`
+}
@@ -0,0 +1,121 @@
+package providers
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Call is a request passing through the hooks of a MiddlewareProvider. Duration, ResponseSize and
+// Err are set before the After hooks.
+type Call struct {
+	Method       string
+	Params       interface{}
+	ChainId      string // "chainId" of the params, empty if there's none
+	Start        time.Time
+	Duration     time.Duration
+	ResponseSize int // bytes of the response result
+	Err          error
+
+	values map[interface{}]interface{}
+}
+
+// SetValue saves a value of the call, such as the span started in the Before hook
+func (c *Call) SetValue(key, value interface{}) {
+	if c.values == nil {
+		c.values = make(map[interface{}]interface{})
+	}
+	c.values[key] = value
+}
+
+func (c *Call) Value(key interface{}) interface{} {
+	return c.values[key]
+}
+
+// Hook is called before and after each request
+type Hook interface {
+	Before(call *Call)
+	After(call *Call)
+}
+
+// HookFuncs is a Hook of functions, nil functions are skipped
+type HookFuncs struct {
+	BeforeFunc func(call *Call)
+	AfterFunc  func(call *Call)
+}
+
+func (h HookFuncs) Before(call *Call) {
+	if h.BeforeFunc != nil {
+		h.BeforeFunc(call)
+	}
+}
+
+func (h HookFuncs) After(call *Call) {
+	if h.AfterFunc != nil {
+		h.AfterFunc(call)
+	}
+}
+
+// MiddlewareProvider calls the Before hooks in order and the After hooks in reverse order around
+// the requests of the provider
+type MiddlewareProvider struct {
+	provider ProviderInterface
+	hooks    []Hook
+}
+
+func NewMiddlewareProvider(provider ProviderInterface, hooks ...Hook) *MiddlewareProvider {
+	return &MiddlewareProvider{provider: provider, hooks: hooks}
+}
+
+// Use appends the hooks, it should be called before sending requests
+func (m *MiddlewareProvider) Use(hooks ...Hook) {
+	m.hooks = append(m.hooks, hooks...)
+}
+
+func (m *MiddlewareProvider) SendRequest(v interface{}, method string, params interface{}) error {
+	call := &Call{Method: method, Params: params, ChainId: chainIdOf(params), Start: time.Now()}
+	for _, hook := range m.hooks {
+		hook.Before(call)
+	}
+	var raw json.RawMessage
+	call.Err = m.provider.SendRequest(&raw, method, params)
+	call.ResponseSize = len(raw)
+	if call.Err == nil && len(raw) > 0 {
+		call.Err = json.Unmarshal(raw, v)
+	}
+	call.Duration = time.Since(call.Start)
+	for i := len(m.hooks) - 1; i >= 0; i-- {
+		m.hooks[i].After(call)
+	}
+	return call.Err
+}
+
+func (m *MiddlewareProvider) Close() error {
+	return m.provider.Close()
+}
+
+// chainIdOf returns the "chainId" of the params in string or number
+func chainIdOf(params interface{}) string {
+	if params == nil {
+		return ""
+	}
+	b, err := json.Marshal(params)
+	if err != nil || len(b) == 0 || b[0] != '{' {
+		return ""
+	}
+	var p struct {
+		ChainId json.RawMessage `json:"chainId"`
+	}
+	if json.Unmarshal(b, &p) != nil || len(p.ChainId) == 0 {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(p.ChainId, &s) == nil {
+		return s
+	}
+	var n uint64
+	if json.Unmarshal(p.ChainId, &n) == nil {
+		return strconv.FormatUint(n, 10)
+	}
+	return ""
+}
@@ -0,0 +1,277 @@
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/ThinkiumGroup/web3.go/web3/providers/util"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit is a token bucket of Rate requests per second with the capacity of Burst, a zero Rate
+// is unlimited
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// DefaultPriorities makes the transactions sent before the queued reads
+var DefaultPriorities = map[string]int{
+	"SendTx":          10,
+	"CallTransaction": 5,
+}
+
+type RateLimitOptions struct {
+	Endpoint    RateLimit            // limit of all requests to the endpoint
+	Methods     map[string]RateLimit // limits of the methods
+	MaxInFlight int                  // requests sent concurrently, 0 is unlimited
+	Priorities  map[string]int       // queued requests of higher priorities are sent first, DefaultPriorities if nil
+	MinBackoff  time.Duration        // first backoff after rate limited, 500ms by default
+	MaxBackoff  time.Duration        // 30s by default
+	MaxRetries  int                  // retries of a rate limited request, 3 by default, negative for no retry
+}
+
+// rateLimitPatterns are the lower-case substrings of the rate limit messages of the node
+var rateLimitPatterns = []string{"rate limit", "too many requests", "too frequent", "request limit"}
+
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit, now time.Time) *tokenBucket {
+	if limit.Rate <= 0 {
+		return nil
+	}
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: limit.Rate, burst: burst, tokens: burst, last: now}
+}
+
+// wait returns how long until a token is available, nil bucket is unlimited
+func (b *tokenBucket) wait(now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+	if now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) take() {
+	if b != nil {
+		b.tokens--
+	}
+}
+
+type waiter struct {
+	method   string
+	priority int
+	seq      uint64
+	ready    chan struct{}
+}
+
+// RateLimitedProvider queues the requests to the provider by the token buckets of the endpoint and
+// the methods, and the cap of in-flight requests. Queued requests are sent in the order of priorities.
+// When the node responds 429 or a rate limit ErrMsg, all requests back off exponentially, and the
+// rate limited request is retried.
+type RateLimitedProvider struct {
+	provider ProviderInterface
+	opts     RateLimitOptions
+
+	lock         sync.Mutex
+	endpoint     *tokenBucket
+	methods      map[string]*tokenBucket
+	waiters      []*waiter
+	seq          uint64
+	inFlight     int
+	backoff      time.Duration
+	backoffUntil time.Time
+	timerAt      time.Time
+}
+
+func NewRateLimitedProvider(provider ProviderInterface, opts RateLimitOptions) *RateLimitedProvider {
+	if opts.Priorities == nil {
+		opts.Priorities = DefaultPriorities
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = 500 * time.Millisecond
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 30 * time.Second
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = 3
+	}
+	now := time.Now()
+	p := &RateLimitedProvider{
+		provider: provider,
+		opts:     opts,
+		endpoint: newTokenBucket(opts.Endpoint, now),
+		methods:  make(map[string]*tokenBucket, len(opts.Methods)),
+	}
+	for method, limit := range opts.Methods {
+		p.methods[method] = newTokenBucket(limit, now)
+	}
+	return p
+}
+
+// Pending returns the count of the queued requests
+func (p *RateLimitedProvider) Pending() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return len(p.waiters)
+}
+
+func (p *RateLimitedProvider) SendRequest(v interface{}, method string, params interface{}) error {
+	for retry := 0; ; retry++ {
+		p.acquire(method)
+		var raw json.RawMessage
+		err := p.provider.SendRequest(&raw, method, params)
+		limited := isRateLimited(err, raw)
+		p.release(limited)
+		if limited && retry < p.opts.MaxRetries {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if len(raw) == 0 {
+			return nil
+		}
+		return json.Unmarshal(raw, v)
+	}
+}
+
+func (p *RateLimitedProvider) Close() error {
+	return p.provider.Close()
+}
+
+func (p *RateLimitedProvider) acquire(method string) {
+	p.lock.Lock()
+	p.seq++
+	w := &waiter{method: method, priority: p.opts.Priorities[method], seq: p.seq, ready: make(chan struct{})}
+	i := sort.Search(len(p.waiters), func(i int) bool {
+		return p.waiters[i].priority < w.priority
+	})
+	p.waiters = append(p.waiters, nil)
+	copy(p.waiters[i+1:], p.waiters[i:])
+	p.waiters[i] = w
+	p.dispatch()
+	p.lock.Unlock()
+	<-w.ready
+}
+
+func (p *RateLimitedProvider) release(limited bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.inFlight--
+	if limited {
+		p.backoff *= 2
+		if p.backoff < p.opts.MinBackoff {
+			p.backoff = p.opts.MinBackoff
+		}
+		if p.backoff > p.opts.MaxBackoff {
+			p.backoff = p.opts.MaxBackoff
+		}
+		p.backoffUntil = time.Now().Add(p.backoff)
+	} else {
+		p.backoff /= 2
+	}
+	p.dispatch()
+}
+
+// dispatch sends the queued requests allowed now, and sets a timer for the next one. A request
+// waiting for its method bucket doesn't block the requests of the other methods.
+func (p *RateLimitedProvider) dispatch() {
+	for len(p.waiters) > 0 {
+		if p.opts.MaxInFlight > 0 && p.inFlight >= p.opts.MaxInFlight {
+			return
+		}
+		now := time.Now()
+		if now.Before(p.backoffUntil) {
+			p.wakeAfter(p.backoffUntil.Sub(now))
+			return
+		}
+		pick, minWait := -1, time.Duration(-1)
+		for i, w := range p.waiters {
+			wait := p.methods[w.method].wait(now)
+			if wait == 0 {
+				pick = i
+				break
+			}
+			if minWait < 0 || wait < minWait {
+				minWait = wait
+			}
+		}
+		if pick < 0 {
+			p.wakeAfter(minWait)
+			return
+		}
+		if wait := p.endpoint.wait(now); wait > 0 {
+			p.wakeAfter(wait)
+			return
+		}
+		w := p.waiters[pick]
+		p.waiters = append(p.waiters[:pick], p.waiters[pick+1:]...)
+		p.endpoint.take()
+		p.methods[w.method].take()
+		p.inFlight++
+		close(w.ready)
+	}
+}
+
+func (p *RateLimitedProvider) wakeAfter(d time.Duration) {
+	at := time.Now().Add(d)
+	if !p.timerAt.IsZero() && !p.timerAt.After(at) && time.Now().Before(p.timerAt) {
+		// an earlier timer is pending
+		return
+	}
+	p.timerAt = at
+	time.AfterFunc(d, func() {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		if p.timerAt.Equal(at) {
+			p.timerAt = time.Time{}
+		}
+		p.dispatch()
+	})
+}
+
+// isRateLimited checks the 429 status and the rate limit messages of the node
+func isRateLimited(err error, raw json.RawMessage) bool {
+	if err != nil {
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests {
+			return true
+		}
+		return hasRateLimitMessage(err.Error())
+	}
+	msg, err := util.ResultErrMsg(raw)
+	return err == nil && hasRateLimitMessage(msg)
+}
+
+func hasRateLimitMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, pattern := range rateLimitPatterns {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package providers
+
+import (
+	"errors"
+	"io"
+	"net"
+	"time"
+)
+
+type RetryOptions struct {
+	MaxRetries int           // retries after the first request, 0 is no retry
+	Backoff    time.Duration // wait before the first retry, doubled after each retry, 200ms by default
+	MaxBackoff time.Duration // 5s by default
+}
+
+// RetryProvider retries the requests failed by the transport, such as refused connections, timeouts
+// and http 5xx status. Errors responded by the node are returned without retry. SendTx is never
+// retried, since the transaction may be accepted by the node even if the response is lost, and
+// sending it again fails for the used nonce instead of returning its hash.
+type RetryProvider struct {
+	provider ProviderInterface
+	opts     RetryOptions
+}
+
+func NewRetryProvider(provider ProviderInterface, opts RetryOptions) *RetryProvider {
+	if opts.Backoff <= 0 {
+		opts.Backoff = 200 * time.Millisecond
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 5 * time.Second
+	}
+	return &RetryProvider{provider: provider, opts: opts}
+}
+
+func (p *RetryProvider) SendRequest(v interface{}, method string, params interface{}) error {
+	if noRetryMethods[method] {
+		return p.provider.SendRequest(v, method, params)
+	}
+	backoff := p.opts.Backoff
+	for retry := 0; ; retry++ {
+		err := p.provider.SendRequest(v, method, params)
+		if err == nil || retry >= p.opts.MaxRetries || !isTransportError(err) {
+			return err
+		}
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > p.opts.MaxBackoff {
+			backoff = p.opts.MaxBackoff
+		}
+	}
+}
+
+// noRetryMethods are the methods whose requests may be executed by the node before the failure
+var noRetryMethods = map[string]bool{"SendTx": true}
+
+func (p *RetryProvider) Close() error {
+	return p.provider.Close()
+}
+
+func isTransportError(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
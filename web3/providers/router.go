@@ -0,0 +1,50 @@
+package providers
+
+import "errors"
+
+// ChainRouter sends the requests to the providers of the chains by the "chainId" of the params, and
+// the requests of the other chains or without chain id to the default provider
+type ChainRouter struct {
+	Default ProviderInterface
+	Chains  map[string]ProviderInterface
+}
+
+func NewChainRouter(defaultProvider ProviderInterface, chains map[string]ProviderInterface) *ChainRouter {
+	if chains == nil {
+		chains = make(map[string]ProviderInterface)
+	}
+	return &ChainRouter{Default: defaultProvider, Chains: chains}
+}
+
+// Provider returns the provider of the chain
+func (r *ChainRouter) Provider(chainId string) (ProviderInterface, error) {
+	if p, ok := r.Chains[chainId]; ok {
+		return p, nil
+	}
+	if r.Default == nil {
+		return nil, errors.New("no provider of chain " + chainId)
+	}
+	return r.Default, nil
+}
+
+func (r *ChainRouter) SendRequest(v interface{}, method string, params interface{}) error {
+	p, err := r.Provider(chainIdOf(params))
+	if err != nil {
+		return err
+	}
+	return p.SendRequest(v, method, params)
+}
+
+// Close closes all providers, and returns the first error
+func (r *ChainRouter) Close() error {
+	var first error
+	if r.Default != nil {
+		first = r.Default.Close()
+	}
+	for _, p := range r.Chains {
+		if err := p.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
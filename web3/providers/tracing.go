@@ -0,0 +1,131 @@
+package providers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// TraceID and SpanID are random ids of 16 and 8 bytes, printed in hex
+type (
+	TraceID [16]byte
+	SpanID  [8]byte
+)
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+type SpanStatus int
+
+const (
+	StatusUnset SpanStatus = iota
+	StatusOk
+	StatusError
+)
+
+// Span is a finished client span of a request, with the method, the chain id and the response
+// size in the Attributes
+type Span struct {
+	Name              string
+	TraceID           TraceID
+	SpanID            SpanID
+	ParentSpanID      SpanID // zero if it's a root span
+	StartTime         time.Time
+	EndTime           time.Time
+	Attributes        map[string]interface{}
+	Status            SpanStatus
+	StatusDescription string
+}
+
+// SpanExporter receives the finished spans
+type SpanExporter interface {
+	ExportSpans(ctx context.Context, spans []*Span) error
+	Shutdown(ctx context.Context) error
+}
+
+// InMemoryExporter keeps the exported spans, for tests
+type InMemoryExporter struct {
+	lock  sync.Mutex
+	spans []*Span
+}
+
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+func (e *InMemoryExporter) ExportSpans(ctx context.Context, spans []*Span) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *InMemoryExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// Spans returns the exported spans in order
+func (e *InMemoryExporter) Spans() []*Span {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return append([]*Span(nil), e.spans...)
+}
+
+func (e *InMemoryExporter) Reset() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.spans = nil
+}
+
+type spanKey struct{}
+
+// TracingHook starts a client span for each request and exports it when the request finishes.
+// Spans are in the trace of Parent if it's set, or each request starts a new trace.
+type TracingHook struct {
+	Exporter SpanExporter
+	Parent   *Span
+}
+
+func NewTracingHook(exporter SpanExporter) *TracingHook {
+	return &TracingHook{Exporter: exporter}
+}
+
+func (h *TracingHook) Before(call *Call) {
+	span := &Span{
+		Name:      call.Method,
+		StartTime: call.Start,
+		Attributes: map[string]interface{}{
+			"rpc.system":          "jsonrpc",
+			"rpc.method":          call.Method,
+			"rpc.jsonrpc.version": "2.0",
+		},
+	}
+	if call.ChainId != "" {
+		span.Attributes["thk.chain_id"] = call.ChainId
+	}
+	if h.Parent != nil {
+		span.TraceID, span.ParentSpanID = h.Parent.TraceID, h.Parent.SpanID
+	} else {
+		_, _ = rand.Read(span.TraceID[:])
+	}
+	_, _ = rand.Read(span.SpanID[:])
+	call.SetValue(spanKey{}, span)
+}
+
+func (h *TracingHook) After(call *Call) {
+	span, ok := call.Value(spanKey{}).(*Span)
+	if !ok {
+		return
+	}
+	span.EndTime = call.Start.Add(call.Duration)
+	span.Attributes["rpc.response.size"] = call.ResponseSize
+	if call.Err != nil {
+		span.Status, span.StatusDescription = StatusError, call.Err.Error()
+	} else {
+		span.Status = StatusOk
+	}
+	_ = h.Exporter.ExportSpans(context.Background(), []*Span{span})
+}
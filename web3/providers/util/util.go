@@ -1,6 +1,82 @@
 package util
 
+import (
+	"encoding/json"
+	"strconv"
+)
+
+const Version = "2.0"
+
+// JsonParam is the JSON-RPC 2.0 request
 type JsonParam struct {
-	Method string      `json:"method"`
-	Params interface{} `json:"params"`
+	Version string      `json:"jsonrpc,omitempty"`
+	ID      uint64      `json:"id,omitempty"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// JsonResult is the JSON-RPC 2.0 response. The legacy node responds the result without the envelope,
+// which has no jsonrpc version.
+type JsonResult struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *JsonError      `json:"error"`
+}
+
+// IsEnvelope returns whether the response is a JSON-RPC 2.0 envelope
+func (r *JsonResult) IsEnvelope() bool {
+	return r.Version != ""
+}
+
+// HasID checks the id of the response, which may be a number or a string
+func (r *JsonResult) HasID(id uint64) bool {
+	var n uint64
+	if err := json.Unmarshal(r.ID, &n); err == nil {
+		return n == id
+	}
+	var s string
+	if err := json.Unmarshal(r.ID, &s); err == nil {
+		return s == strconv.FormatUint(id, 10)
+	}
+	return false
+}
+
+// JsonError is the error object of JSON-RPC 2.0
+type JsonError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *JsonError) Error() string {
+	return e.Message + " (code " + strconv.Itoa(e.Code) + ")"
+}
+
+// ResultErrMsg returns the error message in a result of the legacy node, which is the ErrMsg field
+// or the error field of the object. It's empty if the result is not an object, and the error is
+// not nil if the object is malformed.
+func ResultErrMsg(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || raw[0] != '{' {
+		return "", nil
+	}
+	var res struct {
+		ErrMsg string          `json:"ErrMsg"`
+		Error  json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return "", err
+	}
+	if res.ErrMsg != "" || len(res.Error) == 0 || string(res.Error) == "null" {
+		return res.ErrMsg, nil
+	}
+	var obj JsonError
+	if json.Unmarshal(res.Error, &obj) == nil && obj.Message != "" {
+		return obj.Message, nil
+	}
+	var msg string
+	if json.Unmarshal(res.Error, &msg) == nil && msg != "" {
+		return msg, nil
+	}
+	return string(res.Error), nil
 }
@@ -0,0 +1,122 @@
+package thk
+
+import (
+	"errors"
+	"github.com/ThinkiumGroup/web3.go/common/hexutil"
+	"github.com/ThinkiumGroup/web3.go/web3/dto"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/abi"
+	"strings"
+	"sync"
+)
+
+var ErrUnknownInput = errors.New("no registered abi matches the input")
+
+// AbiRegistry holds the known contracts, and decodes the inputs of the transactions sent to them
+type AbiRegistry struct {
+	lock      sync.RWMutex
+	byAddress map[string]*Contract // contract address (lower case) -> contract
+	byCode    map[string]*Contract // deploy bytecode (lower case with 0x) -> contract
+	generic   []*Contract          // abis matched by method selector for any address, such as ERC20
+}
+
+func NewAbiRegistry() *AbiRegistry {
+	return &AbiRegistry{
+		byAddress: make(map[string]*Contract),
+		byCode:    make(map[string]*Contract),
+	}
+}
+
+// Register binds the contract abi to the deployed address
+func (r *AbiRegistry) Register(address string, contract *Contract) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.byAddress[strings.ToLower(address)] = contract
+}
+
+// RegisterCode makes the deploy transactions with the bytecode decodable, the bytecode is in hex
+// with or without 0x, such as the output of solc --bin
+func (r *AbiRegistry) RegisterCode(bytecode string, contract *Contract) {
+	code := strings.ToLower(strings.TrimSpace(bytecode))
+	if !strings.HasPrefix(code, "0x") {
+		code = "0x" + code
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.byCode[code] = contract
+}
+
+// RegisterGeneric adds an abi which is tried for the transactions sent to unregistered addresses
+func (r *AbiRegistry) RegisterGeneric(contract *Contract) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.generic = append(r.generic, contract)
+}
+
+// Decode decodes the input of a transaction sent to address. An empty address means deploying.
+func (r *AbiRegistry) Decode(address string, input string) (*abi.DecodedInput, error) {
+	if input == "" || input == "0x" {
+		return nil, ErrUnknownInput
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if isDeployAddress(address) {
+		// the longest bytecode is matched, since a bytecode may be the prefix of another one, such
+		// as the same contract with appended metadata
+		lowerInput := strings.ToLower(input)
+		var matched string
+		for code := range r.byCode {
+			if len(code) > len(matched) && strings.HasPrefix(lowerInput, code) {
+				matched = code
+			}
+		}
+		if matched == "" {
+			return nil, ErrUnknownInput
+		}
+		return r.byCode[matched].DecodeDeployInput(input, matched)
+	}
+	if contract, ok := r.byAddress[strings.ToLower(address)]; ok {
+		return contract.DecodeInput(input)
+	}
+	data, err := hexutil.Decode(input)
+	if err != nil {
+		return nil, err
+	}
+	for _, contract := range r.generic {
+		if _, err := contract.abi.MethodById(data); err == nil {
+			return contract.abi.DecodeInput(data)
+		}
+	}
+	return nil, ErrUnknownInput
+}
+
+// DecodeTx decodes the input of a transaction fetched from the chain
+func (r *AbiRegistry) DecodeTx(tx *dto.TransactionResult) (*abi.DecodedInput, error) {
+	return r.Decode(tx.To, tx.Input)
+}
+
+// DecodedTx is a transaction in a block with its decoded input. Input is nil when the
+// transaction is not a call to a known contract, and Err records why.
+type DecodedTx struct {
+	Tx    *dto.TransactionResult
+	Input *abi.DecodedInput
+	Err   error
+}
+
+// DecodeBlockTxs decodes the inputs of the transactions returned by GetBlockTxs
+func (r *AbiRegistry) DecodeBlockTxs(txs *dto.BlockTxs) []DecodedTx {
+	if txs == nil {
+		return nil
+	}
+	ret := make([]DecodedTx, len(txs.AccountChanges))
+	for i := range txs.AccountChanges {
+		tx := &txs.AccountChanges[i]
+		ret[i].Tx = tx
+		ret[i].Input, ret[i].Err = r.DecodeTx(tx)
+	}
+	return ret
+}
+
+func isDeployAddress(address string) bool {
+	return address == "" || address == "0x"
+}
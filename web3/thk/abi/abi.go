@@ -12,6 +12,7 @@ type ABI struct {
 	Constructor Method
 	Methods     map[string]Method
 	Events      map[string]Event
+	Errors      map[string]Error
 }
 
 func JSON(reader io.Reader) (ABI, error) {
@@ -77,22 +78,30 @@ func (abi ABI) UnpackIntoMap(v map[string]interface{}, name string, data []byte)
 	return fmt.Errorf("abi: could not locate named method or event")
 }
 
-func (abi *ABI) UnmarshalJSON(data []byte) error {
-	var fields []struct {
-		Type      string
-		Name      string
-		Constant  bool
-		Anonymous bool
-		Inputs    []Argument
-		Outputs   []Argument
-	}
+// abiField is one entry of the abi definition
+type abiField struct {
+	Type            string
+	Name            string
+	Constant        bool
+	StateMutability string
+	Anonymous       bool
+	Inputs          []Argument
+	Outputs         []Argument
+}
 
+func (abi *ABI) UnmarshalJSON(data []byte) error {
+	var fields []abiField
 	if err := json.Unmarshal(data, &fields); err != nil {
 		return err
 	}
+	abi.setFields(fields)
+	return nil
+}
 
+func (abi *ABI) setFields(fields []abiField) {
 	abi.Methods = make(map[string]Method)
 	abi.Events = make(map[string]Event)
+	abi.Errors = make(map[string]Error)
 	for _, field := range fields {
 		switch field.Type {
 		case "constructor":
@@ -103,7 +112,7 @@ func (abi *ABI) UnmarshalJSON(data []byte) error {
 		case "function", "":
 			abi.Methods[field.Name] = Method{
 				Name:    field.Name,
-				Const:   field.Constant,
+				Const:   field.Constant || field.StateMutability == "view" || field.StateMutability == "pure",
 				Inputs:  field.Inputs,
 				Outputs: field.Outputs,
 			}
@@ -113,10 +122,13 @@ func (abi *ABI) UnmarshalJSON(data []byte) error {
 				Anonymous: field.Anonymous,
 				Inputs:    field.Inputs,
 			}
+		case "error":
+			abi.Errors[field.Name] = Error{
+				Name:   field.Name,
+				Inputs: field.Inputs,
+			}
 		}
 	}
-
-	return nil
 }
 
 func (abi *ABI) MethodById(sigdata []byte) (*Method, error) {
@@ -143,6 +155,18 @@ func (abi *ABI) EventById(idBytes []byte) (*Event, error) {
 	return nil, fmt.Errorf("no event with id: %#x", idBytes)
 }
 
+func (abi *ABI) ErrorById(sigdata []byte) (*Error, error) {
+	if len(sigdata) < 4 {
+		return nil, fmt.Errorf("data too short (%d bytes) for abi error lookup", len(sigdata))
+	}
+	for _, e := range abi.Errors {
+		if bytes.Equal(e.Id(), sigdata[:4]) {
+			return &e, nil
+		}
+	}
+	return nil, fmt.Errorf("no error with id: %#x", sigdata[:4])
+}
+
 //inputParam input[4:]
 func (abi ABI) InputUnpack(v map[string]interface{}, name string, inputParam []byte) (err error) {
 	if len(inputParam) == 0 {
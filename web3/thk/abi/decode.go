@@ -0,0 +1,77 @@
+package abi
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// DecodedArgument is an argument decoded from the input of a transaction
+type DecodedArgument struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// DecodedInput is the method and arguments decoded from the input of a transaction
+type DecodedInput struct {
+	Name string            `json:"name"` // method name, empty for constructor
+	Sig  string            `json:"sig"`  // method signature, such as "transfer(address,uint256)"
+	Args []DecodedArgument `json:"args"`
+}
+
+// Map returns the arguments by name
+func (d *DecodedInput) Map() map[string]interface{} {
+	ret := make(map[string]interface{}, len(d.Args))
+	for _, arg := range d.Args {
+		ret[arg.Name] = arg.Value
+	}
+	return ret
+}
+
+func decodeArguments(arguments Arguments, data []byte) ([]DecodedArgument, error) {
+	if len(arguments) == 0 {
+		return nil, nil
+	}
+	if len(data)%32 != 0 {
+		return nil, fmt.Errorf("abi: improperly formatted input")
+	}
+	values, err := arguments.UnpackValues(data)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]DecodedArgument, len(values))
+	for i, value := range values {
+		ret[i] = DecodedArgument{
+			Name:  arguments[i].Name,
+			Type:  arguments[i].Type.String(),
+			Value: value,
+		}
+	}
+	return ret, nil
+}
+
+// DecodeInput finds the called method by the 4 bytes selector of the input, and decodes its arguments
+func (abi *ABI) DecodeInput(input []byte) (*DecodedInput, error) {
+	method, err := abi.MethodById(input)
+	if err != nil {
+		return nil, err
+	}
+	args, err := decodeArguments(method.Inputs, input[4:])
+	if err != nil {
+		return nil, fmt.Errorf("decode input of %s failed: %v", method.Sig(), err)
+	}
+	return &DecodedInput{Name: method.Name, Sig: method.Sig(), Args: args}, nil
+}
+
+// DecodeConstructorInput decodes the constructor arguments appended to the bytecode in the
+// input of a deploy transaction
+func (abi *ABI) DecodeConstructorInput(input []byte, bytecode []byte) (*DecodedInput, error) {
+	if !bytes.HasPrefix(input, bytecode) {
+		return nil, fmt.Errorf("abi: input is not started with the bytecode")
+	}
+	args, err := decodeArguments(abi.Constructor.Inputs, input[len(bytecode):])
+	if err != nil {
+		return nil, fmt.Errorf("decode constructor input failed: %v", err)
+	}
+	return &DecodedInput{Sig: abi.Constructor.Sig(), Args: args}, nil
+}
@@ -13,14 +13,11 @@ type Event struct {
 }
 
 func (e Event) String() string {
-	inputs := make([]string, len(e.Inputs))
-	for i, input := range e.Inputs {
-		inputs[i] = fmt.Sprintf("%v %v", input.Type, input.Name)
-		if input.Indexed {
-			inputs[i] = fmt.Sprintf("%v indexed %v", input.Type, input.Name)
-		}
+	ret := fmt.Sprintf("event %v(%v)", e.Name, humanReadableArguments(e.Inputs))
+	if e.Anonymous {
+		ret += " anonymous"
 	}
-	return fmt.Sprintf("event %v(%v)", e.Name, strings.Join(inputs, ", "))
+	return ret
 }
 
 func (e Event) id() []byte {
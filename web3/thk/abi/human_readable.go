@@ -0,0 +1,277 @@
+package abi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	identifierRegex = regexp.MustCompile("^[a-zA-Z_$][a-zA-Z0-9_$]*$")
+	// modifiers that may appear after the parameter list and carry no abi information
+	ignoredModifiers = map[string]bool{
+		"external": true, "public": true, "internal": true, "private": true,
+		"payable": true, "nonpayable": true, "virtual": true, "override": true,
+	}
+	// data locations that may appear between the type and the name of a parameter
+	dataLocations = map[string]bool{"memory": true, "calldata": true, "storage": true}
+)
+
+// ParseHumanReadable builds the ABI from the human-readable signatures, such as
+//
+//	function transfer(address to, uint256 amount) returns (bool)
+//	event Transfer(address indexed from, address indexed to, uint256 value)
+//	error InsufficientBalance(uint256 available, uint256 required)
+//	constructor(string symbol, uint8 decimals)
+//
+// fallback and receive functions are accepted and ignored just like abi.JSON does.
+func ParseHumanReadable(signatures []string) (ABI, error) {
+	fields := make([]abiField, 0, len(signatures))
+	for _, signature := range signatures {
+		signature = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(signature), ";"))
+		if signature == "" {
+			continue
+		}
+		field, err := parseSignature(signature)
+		if err != nil {
+			return ABI{}, fmt.Errorf("abi: parse %q failed: %v", signature, err)
+		}
+		fields = append(fields, field)
+	}
+	var abi ABI
+	abi.setFields(fields)
+	return abi, nil
+}
+
+func parseSignature(signature string) (abiField, error) {
+	var field abiField
+	open := strings.Index(signature, "(")
+	if open < 0 {
+		return field, fmt.Errorf("missing parameter list")
+	}
+	head := strings.Fields(signature[:open])
+	switch len(head) {
+	case 1:
+		field.Type = head[0]
+	case 2:
+		field.Type, field.Name = head[0], head[1]
+	default:
+		return field, fmt.Errorf("invalid declaration %q", signature[:open])
+	}
+	if field.Type != "function" && field.Type != "event" && field.Type != "error" &&
+		field.Type != "constructor" && field.Type != "fallback" && field.Type != "receive" {
+		return field, fmt.Errorf("unknown declaration type %q", field.Type)
+	}
+	if field.Name != "" && !identifierRegex.MatchString(field.Name) {
+		return field, fmt.Errorf("invalid name %q", field.Name)
+	}
+	if (field.Type == "function" || field.Type == "event" || field.Type == "error") && field.Name == "" {
+		return field, fmt.Errorf("missing name of %s", field.Type)
+	}
+
+	end, err := matchingParen(signature, open)
+	if err != nil {
+		return field, err
+	}
+	inputs, err := parseArguments(signature[open+1:end], field.Type == "event")
+	if err != nil {
+		return field, err
+	}
+	field.Inputs = inputs
+
+	rest := strings.TrimSpace(signature[end+1:])
+	for rest != "" {
+		if strings.HasPrefix(rest, "returns") {
+			if field.Type != "function" {
+				return field, fmt.Errorf("%s can not have returns", field.Type)
+			}
+			rest = strings.TrimSpace(rest[len("returns"):])
+			if !strings.HasPrefix(rest, "(") {
+				return field, fmt.Errorf("missing parameter list of returns")
+			}
+			end, err := matchingParen(rest, 0)
+			if err != nil {
+				return field, err
+			}
+			if field.Outputs, err = parseArguments(rest[1:end], false); err != nil {
+				return field, err
+			}
+			rest = strings.TrimSpace(rest[end+1:])
+			continue
+		}
+		words := strings.SplitN(rest, " ", 2)
+		switch modifier := words[0]; {
+		case modifier == "view" || modifier == "pure" || modifier == "constant":
+			field.Constant = true
+			field.StateMutability = modifier
+		case modifier == "anonymous" && field.Type == "event":
+			field.Anonymous = true
+		case ignoredModifiers[modifier]:
+		default:
+			return field, fmt.Errorf("unknown modifier %q", modifier)
+		}
+		rest = ""
+		if len(words) == 2 {
+			rest = strings.TrimSpace(words[1])
+		}
+	}
+	return field, nil
+}
+
+// matchingParen returns the index of the parenthesis which closes the one at s[open]
+func matchingParen(s string, open int) (int, error) {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return -1, fmt.Errorf("unbalanced parentheses in %q", s)
+}
+
+// splitTopLevel splits the parameter list by the commas which are not in a nested tuple
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func parseArguments(list string, allowIndexed bool) (Arguments, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	params := splitTopLevel(list)
+	arguments := make(Arguments, 0, len(params))
+	for _, param := range params {
+		marshaling, err := parseParameter(param, allowIndexed)
+		if err != nil {
+			return nil, err
+		}
+		typ, err := NewType(marshaling.Type, marshaling.Components)
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, Argument{
+			Name:    marshaling.Name,
+			Type:    typ,
+			Indexed: marshaling.Indexed,
+		})
+	}
+	return arguments, nil
+}
+
+// parseParameter parses "type [indexed] [location] [name]" into the same form as the json abi
+func parseParameter(param string, allowIndexed bool) (ArgumentMarshaling, error) {
+	var arg ArgumentMarshaling
+	param = strings.TrimSpace(param)
+	if param == "" {
+		return arg, fmt.Errorf("empty parameter")
+	}
+	var rest string
+	if strings.HasPrefix(param, "(") || strings.HasPrefix(param, "tuple(") {
+		open := strings.Index(param, "(")
+		end, err := matchingParen(param, open)
+		if err != nil {
+			return arg, err
+		}
+		for _, component := range splitTopLevel(param[open+1 : end]) {
+			c, err := parseParameter(component, false)
+			if err != nil {
+				return arg, err
+			}
+			arg.Components = append(arg.Components, c)
+		}
+		rest = param[end+1:]
+		dims := rest
+		if i := strings.IndexAny(rest, " \t"); i >= 0 {
+			dims = rest[:i]
+		}
+		arg.Type = "tuple" + dims
+		rest = rest[len(dims):]
+	} else {
+		words := strings.Fields(param)
+		arg.Type = normalizeType(words[0])
+		rest = strings.Join(words[1:], " ")
+	}
+
+	for _, word := range strings.Fields(rest) {
+		switch {
+		case word == "indexed":
+			if !allowIndexed {
+				return arg, fmt.Errorf("indexed is only allowed in event")
+			}
+			arg.Indexed = true
+		case dataLocations[word]:
+		case arg.Name == "" && identifierRegex.MatchString(word):
+			arg.Name = word
+		default:
+			return arg, fmt.Errorf("unexpected %q in parameter %q", word, param)
+		}
+	}
+	return arg, nil
+}
+
+// normalizeType converts the alias uint/int to uint256/int256, keeping the array dimensions
+func normalizeType(t string) string {
+	base, dims := t, ""
+	if i := strings.Index(t, "["); i >= 0 {
+		base, dims = t[:i], t[i:]
+	}
+	switch base {
+	case "uint", "int":
+		base += "256"
+	}
+	return base + dims
+}
+
+// humanReadableType renders the type with the names of tuple components, so that it can be
+// parsed back by ParseHumanReadable
+func humanReadableType(t Type) string {
+	switch t.T {
+	case TupleTy:
+		components := make([]string, len(t.TupleElems))
+		for i, elem := range t.TupleElems {
+			components[i] = humanReadableType(*elem) + " " + t.TupleRawNames[i]
+		}
+		return fmt.Sprintf("tuple(%s)", strings.Join(components, ", "))
+	case SliceTy:
+		return humanReadableType(*t.Elem) + "[]"
+	case ArrayTy:
+		return fmt.Sprintf("%s[%d]", humanReadableType(*t.Elem), t.Size)
+	default:
+		return t.String()
+	}
+}
+
+func humanReadableArguments(arguments Arguments) string {
+	params := make([]string, len(arguments))
+	for i, argument := range arguments {
+		params[i] = humanReadableType(argument.Type)
+		if argument.Indexed {
+			params[i] += " indexed"
+		}
+		if len(argument.Name) > 0 {
+			params[i] += " " + argument.Name
+		}
+	}
+	return strings.Join(params, ", ")
+}
@@ -49,23 +49,19 @@ func (method Method) Sig() string {
 	return fmt.Sprintf("%v(%v)", method.Name, strings.Join(types, ","))
 }
 
+// String returns the human-readable signature of the method, which can be parsed by ParseHumanReadable
 func (method Method) String() string {
-	inputs := make([]string, len(method.Inputs))
-	for i, input := range method.Inputs {
-		inputs[i] = fmt.Sprintf("%v %v", input.Type, input.Name)
-	}
-	outputs := make([]string, len(method.Outputs))
-	for i, output := range method.Outputs {
-		outputs[i] = output.Type.String()
-		if len(output.Name) > 0 {
-			outputs[i] += fmt.Sprintf(" %v", output.Name)
-		}
+	if method.Name == "" {
+		return fmt.Sprintf("constructor(%v)", humanReadableArguments(method.Inputs))
 	}
-	constant := ""
+	ret := fmt.Sprintf("function %v(%v)", method.Name, humanReadableArguments(method.Inputs))
 	if method.Const {
-		constant = "constant "
+		ret += " view"
+	}
+	if len(method.Outputs) > 0 {
+		ret += fmt.Sprintf(" returns (%v)", humanReadableArguments(method.Outputs))
 	}
-	return fmt.Sprintf("function %v(%v) %sreturns(%v)", method.Name, strings.Join(inputs, ", "), constant, strings.Join(outputs, ", "))
+	return ret
 }
 
 func (method Method) Id() []byte {
@@ -0,0 +1,178 @@
+package abi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ThinkiumGroup/web3.go/common/hexutil"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ParseArgs converts the arguments in strings, such as the command line arguments, into the values
+// for Pack. Numbers are decimal or hex with "0x", addresses and bytes are hex, and arrays, slices
+// and tuples are in JSON, such as ["0x01", "0x02"] or {"to": "0x...", "amount": "1"}.
+func (arguments Arguments) ParseArgs(values []string) ([]interface{}, error) {
+	if len(values) != len(arguments) {
+		return nil, fmt.Errorf("abi: expect %d arguments, but %d", len(arguments), len(values))
+	}
+	ret := make([]interface{}, len(values))
+	for i, s := range values {
+		var v interface{} = s
+		if t := arguments[i].Type.T; t == SliceTy || t == ArrayTy || t == TupleTy {
+			decoder := json.NewDecoder(strings.NewReader(s))
+			decoder.UseNumber()
+			if err := decoder.Decode(&v); err != nil {
+				return nil, fmt.Errorf("abi: argument %d of %s is not JSON: %v", i, arguments[i].Type, err)
+			}
+		}
+		value, err := ParseValue(arguments[i].Type, v)
+		if err != nil {
+			return nil, fmt.Errorf("abi: argument %d: %v", i, err)
+		}
+		ret[i] = value
+	}
+	return ret, nil
+}
+
+// ParseValue converts the string, json.Number, bool, []interface{} or map[string]interface{} into
+// the Go value of the type
+func ParseValue(t Type, v interface{}) (interface{}, error) {
+	rv, err := parseValue(t, v)
+	if err != nil {
+		return nil, err
+	}
+	return rv.Interface(), nil
+}
+
+func parseValue(t Type, v interface{}) (reflect.Value, error) {
+	switch t.T {
+	case IntTy, UintTy:
+		n, err := parseInteger(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		min, max := big.NewInt(0), new(big.Int).Lsh(big.NewInt(1), uint(t.Size))
+		if t.T == IntTy {
+			max.Rsh(max, 1)
+			min.Neg(max)
+		}
+		if n.Cmp(min) < 0 || n.Cmp(max) >= 0 {
+			return reflect.Value{}, fmt.Errorf("%s overflows %s", n, t)
+		}
+		if t.Type == bigT {
+			return reflect.ValueOf(n), nil
+		}
+		rv := reflect.New(t.Type).Elem()
+		if t.T == UintTy {
+			rv.SetUint(n.Uint64())
+		} else {
+			rv.SetInt(n.Int64())
+		}
+		return rv, nil
+	case BoolTy:
+		switch b := v.(type) {
+		case bool:
+			return reflect.ValueOf(b), nil
+		case string:
+			parsed, err := strconv.ParseBool(b)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("invalid bool %q", b)
+			}
+			return reflect.ValueOf(parsed), nil
+		}
+	case StringTy:
+		if s, ok := v.(string); ok {
+			return reflect.ValueOf(s), nil
+		}
+	case AddressTy, FixedBytesTy, FunctionTy:
+		b, err := parseBytes(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if len(b) != t.Type.Len() {
+			return reflect.Value{}, fmt.Errorf("expect %d bytes of %s, but %d", t.Type.Len(), t, len(b))
+		}
+		rv := reflect.New(t.Type).Elem()
+		reflect.Copy(rv, reflect.ValueOf(b))
+		return rv, nil
+	case BytesTy:
+		b, err := parseBytes(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(b), nil
+	case SliceTy, ArrayTy:
+		list, ok := v.([]interface{})
+		if !ok {
+			break
+		}
+		if t.T == ArrayTy && len(list) != t.Size {
+			return reflect.Value{}, fmt.Errorf("expect %d elements of %s, but %d", t.Size, t, len(list))
+		}
+		rv := reflect.New(t.Type).Elem()
+		if t.T == SliceTy {
+			rv = reflect.MakeSlice(t.Type, len(list), len(list))
+		}
+		for i, item := range list {
+			elem, err := parseValue(*t.Elem, item)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			rv.Index(i).Set(elem)
+		}
+		return rv, nil
+	case TupleTy:
+		fields, ok := v.(map[string]interface{})
+		if !ok {
+			break
+		}
+		rv := reflect.New(t.Type).Elem()
+		for i, name := range t.TupleRawNames {
+			field, ok := fields[name]
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("missing field %s of %s", name, t)
+			}
+			elem, err := parseValue(*t.TupleElems[i], field)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			rv.Field(i).Set(elem)
+		}
+		return rv, nil
+	}
+	return reflect.Value{}, fmt.Errorf("invalid %s %v", t, v)
+}
+
+func parseInteger(v interface{}) (*big.Int, error) {
+	var s string
+	switch n := v.(type) {
+	case string:
+		s = strings.TrimSpace(n)
+	case json.Number:
+		s = n.String()
+	default:
+		return nil, fmt.Errorf("invalid integer %v", v)
+	}
+	digits, base := s, 10
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "-0x") {
+		digits, base = strings.Replace(s, "0x", "", 1), 16
+	}
+	n, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", s)
+	}
+	return n, nil
+}
+
+func parseBytes(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid hex %v", v)
+	}
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		s = "0x" + s
+	}
+	return hexutil.Decode(s)
+}
@@ -0,0 +1,169 @@
+package abi
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/ThinkiumGroup/web3.go/common"
+	"github.com/ThinkiumGroup/web3.go/common/hexutil"
+	"math/big"
+	"strings"
+)
+
+const (
+	PanicMethodId = "0x4e487b71"
+)
+
+var (
+	// ErrorError is the builtin Error(string) used by require/revert with a message
+	ErrorError Error
+	// PanicError is the builtin Panic(uint256) raised by assert, overflow, division by zero...
+	PanicError Error
+)
+
+// panicReasons maps Solidity panic codes to their meanings
+var panicReasons = map[uint64]string{
+	0x00: "generic panic",
+	0x01: "assert(false)",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "enum overflow",
+	0x22: "invalid encoded storage byte array accessed",
+	0x31: "out-of-bounds array access; popping on an empty array",
+	0x32: "out-of-bounds access of an array or bytesN",
+	0x41: "out of memory",
+	0x51: "uninitialized function",
+}
+
+func init() {
+	typeString, _ := NewType("string", nil)
+	typeUint256, _ := NewType("uint256", nil)
+	ErrorError = Error{
+		Name:   "Error",
+		Inputs: Arguments{{Name: "reason", Type: typeString}},
+	}
+	PanicError = Error{
+		Name:   "Panic",
+		Inputs: Arguments{{Name: "code", Type: typeUint256}},
+	}
+}
+
+// Error is a custom error declared in the contract, e.g. `error InsufficientBalance(uint256 available, uint256 required)`
+type Error struct {
+	Name   string
+	Inputs Arguments
+}
+
+func (e Error) Sig() string {
+	types := make([]string, len(e.Inputs))
+	for i, input := range e.Inputs {
+		types[i] = input.Type.String()
+	}
+	return fmt.Sprintf("%v(%v)", e.Name, strings.Join(types, ","))
+}
+
+func (e Error) String() string {
+	return fmt.Sprintf("error %v(%v)", e.Name, humanReadableArguments(e.Inputs))
+}
+
+func (e Error) Id() []byte {
+	return common.SystemHash256([]byte(e.Sig()))[:4]
+}
+
+// Unpack decodes the arguments of the error, data should not contain the 4 bytes selector
+func (e Error) Unpack(data []byte) ([]interface{}, error) {
+	if len(e.Inputs) == 0 {
+		return nil, nil
+	}
+	if len(data)%32 != 0 {
+		return nil, fmt.Errorf("abi: improperly formatted error data: %x", data)
+	}
+	return e.Inputs.UnpackValues(data)
+}
+
+// ContractError is the decoded revert data of a failed contract execution
+type ContractError struct {
+	Name      string                 // "Error", "Panic" or the name of the custom error
+	Sig       string                 // signature of the error, such as "Error(string)"
+	Args      map[string]interface{} // decoded arguments by name
+	Values    []interface{}          // decoded arguments in declaration order
+	Reason    string                 // revert reason of Error(string), or the meaning of the panic code
+	PanicCode *big.Int               // code of Panic(uint256), nil for other errors
+	Data      []byte                 // raw revert data
+}
+
+func (e *ContractError) Error() string {
+	switch {
+	case e.PanicCode != nil:
+		return fmt.Sprintf("execution reverted: panic 0x%x: %s", e.PanicCode, e.Reason)
+	case e.Name == ErrorError.Name:
+		return fmt.Sprintf("execution reverted: %s", e.Reason)
+	case e.Name == "":
+		return fmt.Sprintf("execution reverted: unknown error %s", hexutil.Encode(e.Data))
+	default:
+		return fmt.Sprintf("execution reverted: %s%v", e.Name, e.Values)
+	}
+}
+
+func newContractError(e Error, data []byte) (*ContractError, error) {
+	values, err := e.Unpack(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	ret := &ContractError{
+		Name:   e.Name,
+		Sig:    e.Sig(),
+		Args:   make(map[string]interface{}, len(values)),
+		Values: values,
+		Data:   data,
+	}
+	for i, value := range values {
+		ret.Args[e.Inputs[i].Name] = value
+	}
+	switch e.Name {
+	case ErrorError.Name:
+		ret.Reason, _ = values[0].(string)
+	case PanicError.Name:
+		ret.PanicCode, _ = values[0].(*big.Int)
+		ret.Reason = PanicReason(ret.PanicCode)
+	}
+	return ret, nil
+}
+
+// PanicReason returns the meaning of a Solidity panic code
+func PanicReason(code *big.Int) string {
+	if code != nil && code.IsUint64() {
+		if reason, ok := panicReasons[code.Uint64()]; ok {
+			return reason
+		}
+	}
+	return "unknown panic code"
+}
+
+// UnpackRevert decodes the builtin Error(string) and Panic(uint256) revert data.
+// Data with any other selector is returned as a ContractError without a name.
+func UnpackRevert(data []byte) (*ContractError, error) {
+	return unpackRevert(nil, data)
+}
+
+// UnpackError decodes revert data by the builtin errors and the errors declared in the abi
+func (abi *ABI) UnpackError(data []byte) (*ContractError, error) {
+	return unpackRevert(abi, data)
+}
+
+func unpackRevert(abi *ABI, data []byte) (*ContractError, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("data too short (%d bytes) for revert data", len(data))
+	}
+	switch {
+	case bytes.Equal(data[:4], hexutil.MustDecode(ErrorMethodId)):
+		return newContractError(ErrorError, data)
+	case bytes.Equal(data[:4], hexutil.MustDecode(PanicMethodId)):
+		return newContractError(PanicError, data)
+	}
+	if abi != nil {
+		if e, err := abi.ErrorById(data); err == nil {
+			return newContractError(*e, data)
+		}
+	}
+	return &ContractError{Data: data}, nil
+}
@@ -1,7 +1,6 @@
 package thk
 
 import (
-	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -14,6 +13,7 @@ import (
 	"strconv"
 )
 
+// addresses of util.DefaultSystemContracts, the transfers of Thk use the SystemContracts of its Network
 const (
 	SystemContractAddressWithdraw = "0x0000000000000000000000000000000000020000"
 	SystemContractAddressDeposit  = "0x0000000000000000000000000000000000030000"
@@ -29,72 +29,85 @@ type CashCheque struct {
 	To           string `json:"to"`
 	ExpireHeight string `json:"expireheight"`
 	Value        string `json:"value"`
+	// the following fields are encoded in the v1 cheque, a cheque without them is encoded as v0
+	ParentChain string `json:"parentChain,omitempty"` // parent chain id of the to chain
+	IsShard     bool   `json:"isShard,omitempty"`     // whether the to chain is a shard
+	UserLocal   bool   `json:"uselocal,omitempty"`    // whether the value is the local currency, CurrencyId is required if true
+	CurrencyId  string `json:"currencyId,omitempty"`  // id of the local currency
 }
 
 func (c *CashCheque) Encode() (string, error) {
-	fromBytes, err := hexutil.Decode(c.From)
+	cashCheque, err := c.toCashCheck()
 	if err != nil {
 		return "", err
 	}
-	toBytes, err := hexutil.Decode(c.To)
+	chequeBytes, err := rtl.Marshal(cashCheque)
 	if err != nil {
 		return "", err
 	}
+	return hexutil.Encode(chequeBytes), nil
+}
+
+func (c *CashCheque) toCashCheck() (*CashCheck, error) {
+	fromBytes, err := hexutil.Decode(c.From)
+	if err != nil {
+		return nil, err
+	}
+	toBytes, err := hexutil.Decode(c.To)
+	if err != nil {
+		return nil, err
+	}
 	bigValue, ok := big.NewInt(0).SetString(c.Value, 10)
 	if !ok {
-		return "", fmt.Errorf("cheque value error :%v", c.Value)
+		return nil, fmt.Errorf("cheque value error :%v", c.Value)
 	}
-	fromChainId, err := strconv.Atoi(c.FromChainId)
+	fromChainId, err := strconv.ParseUint(c.FromChainId, 10, 32)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	toChainId, err := strconv.Atoi(c.ToChainId)
+	toChainId, err := strconv.ParseUint(c.ToChainId, 10, 32)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	nonce, err := strconv.Atoi(c.Nonce)
+	nonce, err := strconv.ParseUint(c.Nonce, 10, 64)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	expireHeight, err := strconv.Atoi(c.ExpireHeight)
+	expireHeight, err := strconv.ParseUint(c.ExpireHeight, 10, 64)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	var parentChain, currencyId uint64
+	if c.ParentChain != "" {
+		if parentChain, err = strconv.ParseUint(c.ParentChain, 10, 32); err != nil {
+			return nil, fmt.Errorf("cheque parent chain error: %v", err)
+		}
+	}
+	if c.CurrencyId != "" {
+		if currencyId, err = strconv.ParseUint(c.CurrencyId, 10, 16); err != nil {
+			return nil, fmt.Errorf("cheque currency id error: %v", err)
+		}
 	}
-	cashCheque := &CashCheck{
+	return &CashCheck{
+		ParentChain:  common.ChainId(parentChain),
+		IsShard:      c.IsShard,
 		FromChain:    common.ChainId(fromChainId),
 		FromAddress:  common.BytesToAddress(fromBytes),
-		Nonce:        uint64(nonce),
+		Nonce:        nonce,
 		ToChain:      common.ChainId(toChainId),
 		ToAddress:    common.BytesToAddress(toBytes),
 		ExpireHeight: common.Height(expireHeight),
+		UserLocal:    c.UserLocal,
 		Amount:       bigValue,
-	}
-	chequeBytes, err := rtl.Marshal(cashCheque)
-	return hexutil.Encode(chequeBytes), nil
+		CurrencyID:   common.ChainId(currencyId),
+	}, nil
 }
 
 func (c *CashCheque) Decode(input string) error {
-	b, err := hexutil.Decode(input)
+	_, cash, err := DecodeChequeInput(input)
 	if err != nil {
 		return err
 	}
-	var cash CashCheck
-	if len(input) < 200 { // TODO
-		_, err = cash.Deserialization(bytes.NewReader(b))
-		if err != nil {
-			return err
-		}
-	} else {
-		request := new(CashRequest)
-		b, err := hexutil.Decode(input)
-		if err != nil {
-			return err
-		}
-		if err = rtl.Unmarshal(b, request); err != nil {
-			return err
-		}
-		cash = *request.Check
-	}
 	c.ChainId = strconv.Itoa(int(cash.FromChain))
 	c.FromChainId = strconv.Itoa(int(cash.FromChain))
 	c.From = hexutil.Encode(cash.FromAddress[:])
@@ -103,6 +116,15 @@ func (c *CashCheque) Decode(input string) error {
 	c.To = hexutil.Encode(cash.ToAddress[:])
 	c.ExpireHeight = strconv.FormatInt(int64(cash.ExpireHeight), 10)
 	c.Value = cash.Amount.String()
+	c.ParentChain, c.CurrencyId = "", ""
+	if cash.ParentChain != 0 {
+		c.ParentChain = strconv.FormatUint(uint64(cash.ParentChain), 10)
+	}
+	if cash.CurrencyID != 0 {
+		c.CurrencyId = strconv.FormatUint(uint64(cash.CurrencyID), 10)
+	}
+	c.IsShard = cash.IsShard
+	c.UserLocal = cash.UserLocal
 	return nil
 }
 
@@ -125,42 +147,40 @@ func (c *CashCheck) String() string {
 		c.ParentChain, c.IsShard, c.FromChain, c.FromAddress[:], c.Nonce, c.ToChain, c.ToAddress[:], c.ExpireHeight, c.UserLocal, math.BigIntForPrint(c.Amount), c.CurrencyID)
 }
 
-func (c *CashCheck) serialPrefix() []byte {
-	var version byte = 0x0
+// serialPrefix returns the header of the v1 cheque, or nil for the v0 cheque which has none of
+// ParentChain, IsShard, UserLocal and CurrencyID
+func (c *CashCheck) serialPrefix() ([]byte, error) {
 	if c.ParentChain == 0 && c.IsShard == false && c.CurrencyID == 0 {
-		if c.UserLocal == false {
-			return nil
-		} else {
-			version = 0x0
-			panic("wrong data: UseLocal==true without CurrencyId")
-		}
-	} else {
-		version = 0x1
-		buf := make([]byte, 13)
-		binary.BigEndian.PutUint32(buf[:4], uint32(common.ReservedMaxChainID))
-		buf[4] = version
 		if c.UserLocal {
-			buf[5] = 0x1
-		} else {
-			buf[5] = 0x0
-		}
-		binary.BigEndian.PutUint32(buf[6:10], uint32(c.ParentChain))
-		if c.IsShard {
-			buf[10] = 0x1
-		} else {
-			buf[10] = 0x0
+			return nil, errors.New("wrong data: UseLocal==true without CurrencyId")
 		}
-		binary.BigEndian.PutUint16(buf[11:13], uint16(c.CurrencyID))
-		return buf
+		return nil, nil
 	}
+	if c.CurrencyID > 0xFFFF {
+		return nil, fmt.Errorf("wrong data: CurrencyId %d out of range", c.CurrencyID)
+	}
+	buf := make([]byte, 13)
+	binary.BigEndian.PutUint32(buf[:4], uint32(common.ReservedMaxChainID))
+	buf[4] = 0x1
+	if c.UserLocal {
+		buf[5] = 0x1
+	}
+	binary.BigEndian.PutUint32(buf[6:10], uint32(c.ParentChain))
+	if c.IsShard {
+		buf[10] = 0x1
+	}
+	binary.BigEndian.PutUint16(buf[11:13], uint16(c.CurrencyID))
+	return buf, nil
 }
 
 func (c *CashCheck) Serialization(w io.Writer) error {
 	buf4 := make([]byte, 4)
 	buf8 := make([]byte, 8)
 
-	var err error
-	prefix := c.serialPrefix()
+	prefix, err := c.serialPrefix()
+	if err != nil {
+		return err
+	}
 	if len(prefix) > 0 {
 		_, err = w.Write(prefix)
 		if err != nil {
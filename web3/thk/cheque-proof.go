@@ -0,0 +1,167 @@
+package thk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/ThinkiumGroup/web3.go/common/hexutil"
+	"github.com/ThinkiumGroup/web3.go/web3/dto"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"github.com/stephenfire/go-rtl"
+	"strconv"
+)
+
+// ChequeInputType is the kind of the input sent to the cross chain system contracts
+type ChequeInputType int
+
+const (
+	ChequeInputCheque ChequeInputType = iota // the encoded cheque, input of SystemContractAddressWithdraw
+	ChequeInputCash                          // CashRequest, input of SystemContractAddressDeposit
+	ChequeInputCancel                        // CancelCashRequest, input of SystemContractAddressCancel
+)
+
+const (
+	// first byte of the rtl encoded CashRequest generated by RpcMakeVccProof
+	cashRequestHeader byte = 0x95
+	// first byte of the rtl encoded cancel request generated by MakeCCCExistenceProof
+	cancelRequestHeader byte = 0x96
+)
+
+func (t ChequeInputType) String() string {
+	switch t {
+	case ChequeInputCheque:
+		return "cheque"
+	case ChequeInputCash:
+		return "cash"
+	case ChequeInputCancel:
+		return "cancel"
+	default:
+		return "ChequeInputType-" + strconv.Itoa(int(t))
+	}
+}
+
+type CancelCashRequest struct {
+	Check *CashCheck `json:"check"`
+}
+
+// DecodeChequeInput decodes the cheque from the input of a withdraw, deposit or cancel transaction.
+// An encoded cheque always starts with 0x00 since chain ids are less than 1<<24, while the requests
+// start with the header of their rtl encoding.
+func DecodeChequeInput(input string) (ChequeInputType, *CashCheck, error) {
+	b, err := hexutil.Decode(input)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(b) == 0 {
+		return 0, nil, errors.New("empty cheque input")
+	}
+	switch b[0] {
+	case cashRequestHeader:
+		request := new(CashRequest)
+		if err = rtl.Unmarshal(b, request); err != nil {
+			return 0, nil, err
+		}
+		if request.Check == nil {
+			return 0, nil, errors.New("no cheque in cash request")
+		}
+		return ChequeInputCash, request.Check, nil
+	case cancelRequestHeader:
+		request := new(CancelCashRequest)
+		if err = rtl.Unmarshal(b, request); err != nil {
+			return 0, nil, err
+		}
+		if request.Check == nil {
+			return 0, nil, errors.New("no cheque in cancel request")
+		}
+		return ChequeInputCancel, request.Check, nil
+	case 0x0:
+		check := new(CashCheck)
+		if _, err = check.Deserialization(bytes.NewReader(b)); err != nil {
+			return 0, nil, err
+		}
+		return ChequeInputCheque, check, nil
+	default:
+		return 0, nil, fmt.Errorf("unknown cheque input header %x", b[0])
+	}
+}
+
+func checkChequeProof(p *dto.ChequeProof, method, chainId string) error {
+	if p.ErrMsg != "" || p.ErrCode != 0 {
+		msg := p.ErrMsg
+		if msg == "" {
+			msg = "error code " + strconv.Itoa(p.ErrCode)
+		}
+		return NewNodeError(method, chainId, p.ErrCode, msg)
+	}
+	if p.Input == "" {
+		return errors.New("no input in proof")
+	}
+	return nil
+}
+
+// VccProof is the result of RpcMakeVccProof, used to cash the cheque on the to chain
+type VccProof struct {
+	dto.ChequeProof
+}
+
+// Request decodes the CashRequest in the input
+func (p *VccProof) Request() (*CashRequest, error) {
+	typ, check, err := DecodeChequeInput(p.Input)
+	if err != nil {
+		return nil, err
+	}
+	if typ != ChequeInputCash {
+		return nil, fmt.Errorf("input of vcc proof is %s", typ)
+	}
+	return &CashRequest{Check: check}, nil
+}
+
+// DepositTransaction builds the unsigned transaction sent by from to the Deposit contract to cash
+// the cheque, nonce is not set
+func (p *VccProof) DepositTransaction(from string, contracts util.SystemContracts) (*util.Transaction, error) {
+	request, err := p.Request()
+	if err != nil {
+		return nil, err
+	}
+	chainId := strconv.Itoa(int(request.Check.ToChain))
+	return &util.Transaction{
+		ChainId: chainId, FromChainId: chainId, ToChainId: chainId, From: from,
+		To: contracts.Deposit, Value: "0", Input: p.Input,
+	}, nil
+}
+
+// CancelProof is the result of MakeCCCExistenceProof, used to cancel the cheque on the from chain.
+// The cheque can not be cancelled if it has been cashed, that is Existence is true.
+type CancelProof struct {
+	dto.ChequeProof
+	Existence bool `json:"existence"`
+}
+
+// Request decodes the CancelCashRequest in the input
+func (p *CancelProof) Request() (*CancelCashRequest, error) {
+	typ, check, err := DecodeChequeInput(p.Input)
+	if err != nil {
+		return nil, err
+	}
+	if typ != ChequeInputCancel {
+		return nil, fmt.Errorf("input of cancel proof is %s", typ)
+	}
+	return &CancelCashRequest{Check: check}, nil
+}
+
+// CancelTransaction builds the unsigned transaction sent by from to the Cancel contract to cancel
+// the cheque, nonce is not set
+func (p *CancelProof) CancelTransaction(from string, contracts util.SystemContracts) (*util.Transaction, error) {
+	if p.Existence {
+		return nil, errors.New("cheque has been cashed")
+	}
+	request, err := p.Request()
+	if err != nil {
+		return nil, err
+	}
+	chainId := strconv.Itoa(int(request.Check.FromChain))
+	return &util.Transaction{
+		ChainId: chainId, FromChainId: chainId, ToChainId: chainId, From: from,
+		To: contracts.Cancel, Value: "0", Input: p.Input,
+	}, nil
+}
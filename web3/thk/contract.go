@@ -1,14 +1,17 @@
 package thk
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/ThinkiumGroup/web3.go/common"
 	"github.com/ThinkiumGroup/web3.go/common/hexutil"
 	"github.com/ThinkiumGroup/web3.go/web3/dto"
 	"github.com/ThinkiumGroup/web3.go/web3/thk/abi"
 	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
 	"math/big"
+	"strconv"
 	"strings"
 )
 
@@ -18,7 +21,12 @@ type Contract struct {
 	functions map[string][]string
 }
 
+// NewContract creates the contract by its abi, which can be either the json abi or the
+// human-readable abi: a json array of signatures, or signatures separated by new lines.
 func (thk *Thk) NewContract(abistr string) (*Contract, error) {
+	if signatures, ok := humanReadableSignatures(abistr); ok {
+		return thk.NewContractFromSignatures(signatures)
+	}
 	contract := new(Contract)
 	var mockInterface interface{}
 	err := json.Unmarshal([]byte(abistr), &mockInterface)
@@ -57,6 +65,53 @@ func (thk *Thk) NewContract(abistr string) (*Contract, error) {
 	return contract, nil
 }
 
+// NewContractFromSignatures creates the contract by the human-readable abi, such as
+// "function transfer(address to, uint256 amount) returns (bool)"
+func (thk *Thk) NewContractFromSignatures(signatures []string) (*Contract, error) {
+	Abi, err := abi.ParseHumanReadable(signatures)
+	if err != nil {
+		return nil, err
+	}
+	contract := new(Contract)
+	contract.functions = make(map[string][]string)
+	contract.functions["constructor"] = argumentTypes(Abi.Constructor.Inputs)
+	for name, method := range Abi.Methods {
+		contract.functions[name] = argumentTypes(method.Inputs)
+	}
+	for name, event := range Abi.Events {
+		contract.functions[name] = argumentTypes(event.Inputs)
+	}
+	contract.abi = Abi
+	contract.super = thk
+	return contract, nil
+}
+
+func argumentTypes(arguments abi.Arguments) []string {
+	types := make([]string, 0, len(arguments))
+	for _, argument := range arguments {
+		types = append(types, argument.Type.String())
+	}
+	return types
+}
+
+// humanReadableSignatures returns the signatures if abistr is not a json abi
+func humanReadableSignatures(abistr string) ([]string, bool) {
+	abistr = strings.TrimSpace(abistr)
+	if strings.HasPrefix(abistr, "[") {
+		var signatures []string
+		if err := json.Unmarshal([]byte(abistr), &signatures); err != nil {
+			return nil, false
+		}
+		return signatures, true
+	}
+	return strings.Split(abistr, "\n"), abistr != ""
+}
+
+// ABI returns the parsed abi of the contract
+func (contract *Contract) ABI() *abi.ABI {
+	return &contract.abi
+}
+
 func (contract *Contract) getHexValue(inputType string, value interface{}) (string, error) {
 
 	var data string
@@ -136,6 +191,84 @@ func (contract *Contract) Deploy(transaction util.Transaction, bytecode string,
 	return contract.super.SendTx(&transaction)
 }
 
+// DeployResult is the result of a deploy transaction which has been packed
+type DeployResult struct {
+	Hash            string        // hash of the deploy transaction
+	Address         string        // address of the deployed contract
+	ExpectedAddress string        // address computed by the sender and nonce before sending
+	Receipt         *dto.TxResult // receipt of the deploy transaction
+}
+
+// DeployAndWait deploys the contract, waits for the transaction to be packed, and checks that
+// code exists at the address of the new contract. The address in the receipt must be the address
+// computed by ContractAddress.
+func (contract *Contract) DeployAndWait(transaction util.Transaction, bytecode string, privateKey string, args ...interface{}) (*DeployResult, error) {
+	expected, err := ContractAddress(transaction.From, transaction.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := contract.Deploy(transaction, bytecode, privateKey, args...)
+	if err != nil {
+		return nil, err
+	}
+	result := &DeployResult{Hash: hash, ExpectedAddress: expected}
+	receipt := util.BlockGetDefault(func() (interface{}, bool) {
+		res, err := contract.super.GetTransactionByHash(transaction.ChainId, hash)
+		if err == nil {
+			return res, true
+		}
+		return nil, false
+	})
+	if receipt == nil {
+		return result, fmt.Errorf("get deploy receipt timeout, chainId:%s, hash:%s", transaction.ChainId, hash)
+	}
+	result.Receipt = receipt.(*dto.TxResult)
+	if err = contract.UnpackError(result.Receipt); err != nil {
+		return result, err
+	}
+	result.Address = result.Receipt.ContractAddress
+	if result.Address == "" {
+		// the code at the expected address is checked below
+		result.Address = expected
+	} else if !strings.EqualFold(result.Address, expected) {
+		return result, fmt.Errorf("contract address %s of deploy receipt is not the expected %s, hash:%s", result.Address, expected, hash)
+	}
+	account, err := contract.super.GetAccount(result.Address, transaction.ChainId)
+	if err != nil {
+		return result, err
+	}
+	if !hasCode(account.CodeHash) {
+		return result, fmt.Errorf("no code at contract address %s, hash:%s", result.Address, hash)
+	}
+	return result, nil
+}
+
+// ContractAddress computes the address of the contract deployed by from with the nonce
+func ContractAddress(from string, nonce string) (string, error) {
+	fromBytes, err := hexutil.Decode(from)
+	if err != nil {
+		return "", err
+	}
+	n, err := strconv.ParseUint(nonce, 10, 64)
+	if err != nil {
+		return "", err
+	}
+	address := util.CreateContractAddress(common.BytesToAddress(fromBytes), n)
+	return hexutil.Encode(address[:]), nil
+}
+
+func hasCode(codeHash []byte) bool {
+	if len(codeHash) == 0 || bytes.Equal(codeHash, common.SystemHash256(nil)) {
+		return false
+	}
+	for _, b := range codeHash {
+		if b != 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func (contract *Contract) Call(transaction util.Transaction, functionName string, args ...interface{}) (*dto.TxResult, error) {
 	fixedArrStrPack, err := contract.abi.Pack(functionName, args...)
 	if err != nil {
@@ -163,6 +296,9 @@ func (contract *Contract) CallAndParse(chainId, contractAddress string, result i
 	if err != nil {
 		return err
 	}
+	if err = contract.UnpackError(receipt); err != nil {
+		return err
+	}
 	err = contract.Parse(receipt.Out, method, result)
 	if err != nil {
 		return err
@@ -170,6 +306,41 @@ func (contract *Contract) CallAndParse(chainId, contractAddress string, result i
 	return nil
 }
 
+// UnpackError returns the *abi.ContractError decoded from the Out of a failed result,
+// including the errors declared in the contract abi. It returns nil if the execution succeeded.
+func (contract *Contract) UnpackError(res *dto.TxResult) error {
+	return unpackTxError(&contract.abi, res)
+}
+
+// UnpackTxError is the same as Contract.UnpackError, but only knows the builtin Error(string)
+// and Panic(uint256)
+func UnpackTxError(res *dto.TxResult) error {
+	return unpackTxError(nil, res)
+}
+
+func unpackTxError(contractAbi *abi.ABI, res *dto.TxResult) error {
+	if res == nil || res.Status == 1 {
+		return nil
+	}
+	out, err := hexutil.Decode(res.Out)
+	if err != nil || len(out) < 4 {
+		if res.Error != "" {
+			return errors.New(res.Error)
+		}
+		return errors.New("execution failed")
+	}
+	var contractErr *abi.ContractError
+	if contractAbi != nil {
+		contractErr, err = contractAbi.UnpackError(out)
+	} else {
+		contractErr, err = abi.UnpackRevert(out)
+	}
+	if err != nil {
+		return err
+	}
+	return contractErr
+}
+
 func (contract *Contract) GetInput(functionName string, args ...interface{}) (string, error) {
 	fixedArrStrPack, err := contract.abi.Pack(functionName, args...)
 	if err != nil {
@@ -177,6 +348,29 @@ func (contract *Contract) GetInput(functionName string, args ...interface{}) (st
 	}
 	return hexutil.Encode(fixedArrStrPack), err
 }
+
+// DecodeInput decodes the method and its arguments from the hex input of a transaction
+func (contract *Contract) DecodeInput(input string) (*abi.DecodedInput, error) {
+	data, err := hexutil.Decode(input)
+	if err != nil {
+		return nil, err
+	}
+	return contract.abi.DecodeInput(data)
+}
+
+// DecodeDeployInput decodes the constructor arguments from the hex input of a deploy transaction
+func (contract *Contract) DecodeDeployInput(input string, bytecode string) (*abi.DecodedInput, error) {
+	data, err := hexutil.Decode(input)
+	if err != nil {
+		return nil, err
+	}
+	code, err := hexutil.Decode(bytecode)
+	if err != nil {
+		return nil, err
+	}
+	return contract.abi.DecodeConstructorInput(data, code)
+}
+
 func (contract *Contract) SendTransaction(transaction util.Transaction) (string, error) {
 	return contract.super.SendTx(&transaction)
 }
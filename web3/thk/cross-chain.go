@@ -0,0 +1,501 @@
+package thk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ThinkiumGroup/web3.go/web3/dto"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TransferStep is the state of a cross chain transfer
+type TransferStep string
+
+const (
+	TransferNew            TransferStep = "new"            // the cheque is not written yet
+	TransferWithdrawSent   TransferStep = "withdrawSent"   // the withdraw transaction is sent on the from chain
+	TransferWithdrawn      TransferStep = "withdrawn"      // the cheque is written, waiting for cashing or cancelling
+	TransferDepositPending TransferStep = "depositPending" // the deposit transaction is being sent on the to chain
+	TransferDepositSent    TransferStep = "depositSent"    // the deposit transaction is sent on the to chain
+	TransferCancelPending  TransferStep = "cancelPending"  // the cancel transaction is being sent on the from chain
+	TransferCancelSent     TransferStep = "cancelSent"     // the cancel transaction is sent on the from chain
+	TransferCashed         TransferStep = "cashed"         // done, the value is deposited on the to chain
+	TransferCancelled      TransferStep = "cancelled"      // done, the value is returned on the from chain
+	TransferFailed         TransferStep = "failed"         // a transaction failed, see Error
+)
+
+// Done reports whether the transfer reaches a final state
+func (s TransferStep) Done() bool {
+	return s == TransferCashed || s == TransferCancelled || s == TransferFailed
+}
+
+// TransferState is the persistent state of a CrossChainTransfer
+type TransferState struct {
+	Step   TransferStep `json:"step"`
+	Cheque *CashCheque  `json:"cheque"`
+	// height of the from chain saved before sending the withdraw transaction, the transaction with
+	// the nonce of the cheque is looked for from this height if the transfer is resumed
+	WithdrawHeight string `json:"withdrawHeight,omitempty"`
+	WithdrawHash   string `json:"withdrawHash,omitempty"`
+	// nonce and height of the chain saved before sending the deposit or cancel transaction, the
+	// transaction with the nonce is looked for from this height if the transfer is resumed
+	SendNonce   string `json:"sendNonce,omitempty"`
+	SendHeight  string `json:"sendHeight,omitempty"`
+	DepositHash string `json:"depositHash,omitempty"`
+	CancelHash  string `json:"cancelHash,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// TransferStore saves the state of a transfer after every step, so that it can be resumed after a crash
+type TransferStore interface {
+	Save(state *TransferState) error
+}
+
+// FileTransferStore saves the state as json in the file at the path
+type FileTransferStore string
+
+func (path FileTransferStore) Save(state *TransferState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := string(path) + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, string(path))
+}
+
+func (path FileTransferStore) Load() (*TransferState, error) {
+	data, err := ioutil.ReadFile(string(path))
+	if err != nil {
+		return nil, err
+	}
+	state := new(TransferState)
+	if err = json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// CrossChainTransfer drives a cross chain transfer by cash cheque:
+//  1. write the cheque by sending it to SystemContractAddressWithdraw on the from chain
+//  2. if the to chain has not passed the ExpireHeight, get the proof by RpcMakeVccProof and
+//     cash the cheque by sending it to SystemContractAddressDeposit on the to chain
+//  3. otherwise get the proof by MakeCCCExistenceProof on the to chain and cancel the cheque
+//     by sending it to SystemContractAddressCancel on the from chain
+//
+// All transactions are sent by the cheque.From and signed with the private key.
+type CrossChainTransfer struct {
+	State        TransferState
+	PollInterval time.Duration // interval between steps in Run, 2 seconds by default
+
+	thk        *Thk
+	privateKey string
+	store      TransferStore
+}
+
+// NewCrossChainTransfer creates a transfer for the cheque, store can be nil if the state needs not to be saved
+func (thk *Thk) NewCrossChainTransfer(cheque *CashCheque, privateKey string, store TransferStore) *CrossChainTransfer {
+	return thk.ResumeCrossChainTransfer(&TransferState{Step: TransferNew, Cheque: cheque}, privateKey, store)
+}
+
+// ResumeCrossChainTransfer continues the transfer from the state saved by the store
+func (thk *Thk) ResumeCrossChainTransfer(state *TransferState, privateKey string, store TransferStore) *CrossChainTransfer {
+	return &CrossChainTransfer{
+		State:        *state,
+		PollInterval: 2 * time.Second,
+		thk:          thk,
+		privateKey:   privateKey,
+		store:        store,
+	}
+}
+
+// Run executes the steps until the transfer is done or ctx is done. The errors of the
+// unfinished steps, such as the proof is not ready yet, are retried.
+func (c *CrossChainTransfer) Run(ctx context.Context) error {
+	var lastErr error
+	for {
+		done, err := c.Step()
+		if done {
+			return err
+		}
+		if err != nil {
+			lastErr = err
+		}
+		select {
+		case <-ctx.Done():
+			if lastErr != nil {
+				return fmt.Errorf("%v, last error: %v", ctx.Err(), lastErr)
+			}
+			return ctx.Err()
+		case <-time.After(c.PollInterval):
+		}
+	}
+}
+
+// Step executes the current step once and saves the new state. done is true when the transfer
+// reaches a final state, and err is not nil when it's failed.
+func (c *CrossChainTransfer) Step() (done bool, err error) {
+	if c.State.Cheque == nil {
+		return true, errors.New("no cheque in the transfer")
+	}
+	step := c.State.Step
+	switch step {
+	case TransferNew:
+		err = c.withdraw()
+	case TransferWithdrawSent:
+		err = c.checkTx(c.State.Cheque.FromChainId, c.State.WithdrawHash, TransferWithdrawn)
+	case TransferWithdrawn:
+		err = c.cashOrCancel()
+	case TransferDepositPending:
+		err = c.resumeSend(c.State.Cheque.ToChainId, c.systemContracts().Deposit, TransferDepositSent, &c.State.DepositHash)
+	case TransferDepositSent:
+		err = c.checkDeposit()
+	case TransferCancelPending:
+		err = c.resumeSend(c.State.Cheque.FromChainId, c.systemContracts().Cancel, TransferCancelSent, &c.State.CancelHash)
+	case TransferCancelSent:
+		err = c.checkCancel()
+	case TransferFailed:
+		return true, errors.New(c.State.Error)
+	case TransferCashed, TransferCancelled:
+		return true, nil
+	default:
+		return true, fmt.Errorf("unknown transfer step: %s", step)
+	}
+	if err != nil {
+		return false, err
+	}
+	if c.State.Step == TransferFailed {
+		return true, errors.New(c.State.Error)
+	}
+	return c.State.Step.Done(), nil
+}
+
+func (c *CrossChainTransfer) moveTo(step TransferStep) error {
+	c.State.Step = step
+	if c.store == nil {
+		return nil
+	}
+	return c.store.Save(&c.State)
+}
+
+func (c *CrossChainTransfer) fail(reason error) error {
+	c.State.Error = reason.Error()
+	return c.moveTo(TransferFailed)
+}
+
+func (c *CrossChainTransfer) withdraw() error {
+	cheque := c.State.Cheque
+	nonce, err := c.thk.GetNonce(cheque.From, cheque.FromChainId)
+	if err != nil {
+		return err
+	}
+	chequeNonce, err := strconv.ParseInt(cheque.Nonce, 10, 64)
+	if err != nil {
+		return err
+	}
+	input, err := cheque.Encode()
+	if err != nil {
+		return err
+	}
+	if nonce > chequeNonce {
+		// the nonce is used, which may be the withdraw transaction sent before the last crash
+		return c.findWithdraw(chequeNonce, input)
+	}
+	stats, err := c.thk.GetStats(cheque.FromChainId)
+	if err != nil {
+		return err
+	}
+	c.State.WithdrawHeight = strconv.Itoa(stats.CurrentHeight)
+	if err = c.moveTo(TransferNew); err != nil {
+		return err
+	}
+	tx := util.Transaction{
+		ChainId: cheque.FromChainId, FromChainId: cheque.FromChainId, ToChainId: cheque.ToChainId, From: cheque.From,
+		To: c.systemContracts().Withdraw, Value: "0", Input: input, Nonce: cheque.Nonce,
+	}
+	hash, err := c.sendTx(&tx)
+	if err != nil {
+		return err
+	}
+	c.State.WithdrawHash = hash
+	return c.moveTo(TransferWithdrawSent)
+}
+
+// findWithdraw looks for the transaction with the nonce of the cheque after WithdrawHeight. The
+// transfer fails if the transaction is not the withdraw of the cheque, or it's never sent by the
+// transfer.
+func (c *CrossChainTransfer) findWithdraw(nonce int64, input string) error {
+	cheque := c.State.Cheque
+	if c.State.WithdrawHeight == "" {
+		return c.fail(fmt.Errorf("nonce %d of the cheque is used by another transaction", nonce))
+	}
+	tx, err := c.findTx(cheque.FromChainId, nonce, c.State.WithdrawHeight)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(tx.To, c.systemContracts().Withdraw) || !strings.EqualFold(tx.Input, input) {
+		return c.fail(fmt.Errorf("nonce %d of the cheque is used by another transaction %s", nonce, tx.Hash))
+	}
+	c.State.WithdrawHash = tx.Hash
+	return c.moveTo(TransferWithdrawSent)
+}
+
+// history returns the transactions sent by the cheque.From on the chain from the height
+func (c *CrossChainTransfer) history(chainId, height string) ([]dto.GetTransactions, error) {
+	start, err := strconv.ParseUint(height, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := c.thk.GetStats(chainId)
+	if err != nil {
+		return nil, err
+	}
+	return c.thk.GetAddressHistory(chainId, c.State.Cheque.From, start, uint64(stats.CurrentHeight),
+		HistoryOptions{Direction: TxOutgoing})
+}
+
+// findTx returns the transaction with the nonce sent by the cheque.From on the chain from the height
+func (c *CrossChainTransfer) findTx(chainId string, nonce int64, height string) (*dto.GetTransactions, error) {
+	txs, err := c.history(chainId, height)
+	if err != nil {
+		return nil, err
+	}
+	for i := range txs {
+		if int64(txs[i].Nonce) == nonce {
+			return &txs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("transaction of nonce %d is not found after height %s", nonce, height)
+}
+
+// cashOrCancel cashes the cheque before the to chain passes its ExpireHeight, or cancels it after that
+func (c *CrossChainTransfer) cashOrCancel() error {
+	cheque := c.State.Cheque
+	expired, err := c.expired()
+	if err != nil {
+		return err
+	}
+	if expired {
+		return c.cancel()
+	}
+	proof, err := c.thk.RpcMakeVccProof(cheque)
+	if err != nil {
+		return fmt.Errorf("make vcc proof failed: %v", err)
+	}
+	tx, err := proof.DepositTransaction(cheque.From, c.systemContracts())
+	if err != nil {
+		return err
+	}
+	return c.sendPending(tx, TransferDepositPending, TransferDepositSent, &c.State.DepositHash)
+}
+
+func (c *CrossChainTransfer) cancel() error {
+	cheque := *c.State.Cheque
+	cheque.ChainId = cheque.ToChainId
+	proof, err := c.thk.MakeCCCExistenceProof(&cheque)
+	if err != nil {
+		return fmt.Errorf("make ccc existence proof failed: %v", err)
+	}
+	if proof.Existence {
+		// cashed by a deposit transaction which is not tracked by this transfer
+		return c.moveTo(TransferCashed)
+	}
+	tx, err := proof.CancelTransaction(cheque.From, c.systemContracts())
+	if err != nil {
+		return err
+	}
+	return c.sendPending(tx, TransferCancelPending, TransferCancelSent, &c.State.CancelHash)
+}
+
+// sendPending saves the nonce and the height of the chain in the pending step before sending the
+// deposit or cancel transaction, and moves to the next step with the hash after it's sent
+func (c *CrossChainTransfer) sendPending(tx *util.Transaction, pending, next TransferStep, hash *string) error {
+	nonce, err := c.thk.GetNonce(tx.From, tx.ChainId)
+	if err != nil {
+		return err
+	}
+	stats, err := c.thk.GetStats(tx.ChainId)
+	if err != nil {
+		return err
+	}
+	tx.Nonce = strconv.FormatInt(nonce, 10)
+	c.State.SendNonce, c.State.SendHeight = tx.Nonce, strconv.Itoa(stats.CurrentHeight)
+	if err = c.moveTo(pending); err != nil {
+		return err
+	}
+	if *hash, err = c.sendTx(tx); err != nil {
+		return err
+	}
+	return c.moveTo(next)
+}
+
+// resumeSend adopts the transaction with the saved nonce if it's sent to the contract, which may be
+// sent before the last crash. Otherwise the transaction is not sent or the nonce is used by another
+// transaction, the transfer goes back to withdrawn to check the cheque and send it again.
+func (c *CrossChainTransfer) resumeSend(chainId, contract string, next TransferStep, hash *string) error {
+	nonce, err := c.thk.GetNonce(c.State.Cheque.From, chainId)
+	if err != nil {
+		return err
+	}
+	sendNonce, err := strconv.ParseInt(c.State.SendNonce, 10, 64)
+	if err != nil {
+		return err
+	}
+	if nonce > sendNonce {
+		tx, err := c.findTx(chainId, sendNonce, c.State.SendHeight)
+		if err != nil {
+			return err
+		}
+		if strings.EqualFold(tx.To, contract) {
+			*hash = tx.Hash
+			return c.moveTo(next)
+		}
+	}
+	return c.moveTo(TransferWithdrawn)
+}
+
+// systemContracts returns the system contracts of the network of Thk
+func (c *CrossChainTransfer) systemContracts() util.SystemContracts {
+	if c.thk.Network == nil {
+		return util.DefaultSystemContracts
+	}
+	return c.thk.Network.SystemContracts
+}
+
+// expired reports whether the height of the to chain is greater than the ExpireHeight of the cheque
+func (c *CrossChainTransfer) expired() (bool, error) {
+	expireHeight, err := strconv.Atoi(c.State.Cheque.ExpireHeight)
+	if err != nil {
+		return false, err
+	}
+	stats, err := c.thk.GetStats(c.State.Cheque.ToChainId)
+	if err != nil {
+		return false, err
+	}
+	return stats.CurrentHeight > expireHeight, nil
+}
+
+// checkDeposit moves to cashed if the deposit succeeded or the cheque has been cashed by another
+// transaction. A failed deposit after the cheque expired goes back to withdrawn to cancel the cheque.
+func (c *CrossChainTransfer) checkDeposit() error {
+	cheque := *c.State.Cheque
+	res, err := c.thk.GetTransactionByHash(cheque.ToChainId, c.State.DepositHash)
+	if err != nil {
+		return err
+	}
+	if res.Status == 1 {
+		return c.moveTo(TransferCashed)
+	}
+	cheque.ChainId = cheque.ToChainId
+	proof, err := c.thk.MakeCCCExistenceProof(&cheque)
+	if err != nil {
+		return fmt.Errorf("make ccc existence proof failed: %v", err)
+	}
+	if proof.Existence {
+		return c.moveTo(TransferCashed)
+	}
+	expired, err := c.expired()
+	if err != nil {
+		return err
+	}
+	if expired {
+		c.State.DepositHash = ""
+		return c.moveTo(TransferWithdrawn)
+	}
+	return c.fail(fmt.Errorf("deposit failed, hash:%s: %v", c.State.DepositHash, UnpackTxError(res)))
+}
+
+// checkCancel moves to cancelled if the cancel succeeded or the cheque has been cancelled by another
+// transaction of the cheque.From, or fails the transfer
+func (c *CrossChainTransfer) checkCancel() error {
+	cheque := c.State.Cheque
+	res, err := c.thk.GetTransactionByHash(cheque.FromChainId, c.State.CancelHash)
+	if err != nil {
+		return err
+	}
+	if res.Status == 1 {
+		return c.moveTo(TransferCancelled)
+	}
+	hash, err := c.findCancel()
+	if err != nil {
+		return err
+	}
+	if hash != "" {
+		c.State.CancelHash = hash
+		return c.moveTo(TransferCancelled)
+	}
+	return c.fail(fmt.Errorf("cancel failed, hash:%s: %v", c.State.CancelHash, UnpackTxError(res)))
+}
+
+// findCancel returns the hash of the successful cancel of the cheque sent by the cheque.From after
+// the cheque is written, or "" if it's not found
+func (c *CrossChainTransfer) findCancel() (string, error) {
+	cheque := c.State.Cheque
+	height := c.State.WithdrawHeight
+	if height == "" {
+		height = c.State.SendHeight
+	}
+	if height == "" {
+		return "", nil
+	}
+	check, err := cheque.toCashCheck()
+	if err != nil {
+		return "", err
+	}
+	txs, err := c.history(cheque.FromChainId, height)
+	if err != nil {
+		return "", err
+	}
+	for _, tx := range txs {
+		if tx.Hash == c.State.CancelHash || !strings.EqualFold(tx.To, c.systemContracts().Cancel) {
+			continue
+		}
+		typ, cancelled, err := DecodeChequeInput(tx.Input)
+		if err != nil || typ != ChequeInputCancel || cancelled.FromChain != check.FromChain ||
+			cancelled.FromAddress != check.FromAddress || cancelled.Nonce != check.Nonce {
+			continue
+		}
+		res, err := c.thk.GetTransactionByHash(cheque.FromChainId, tx.Hash)
+		if err != nil {
+			return "", err
+		}
+		if res.Status == 1 {
+			return tx.Hash, nil
+		}
+	}
+	return "", nil
+}
+
+// checkTx moves to the next step if the transaction succeeded, or fails the transfer
+func (c *CrossChainTransfer) checkTx(chainId, hash string, next TransferStep) error {
+	res, err := c.thk.GetTransactionByHash(chainId, hash)
+	if err != nil {
+		return err
+	}
+	if res.Status != 1 {
+		return c.fail(fmt.Errorf("tx failed, hash:%s: %v", hash, UnpackTxError(res)))
+	}
+	return c.moveTo(next)
+}
+
+func (c *CrossChainTransfer) sendTx(tx *util.Transaction) (string, error) {
+	if tx.Nonce == "" {
+		nonce, err := c.thk.GetNonce(tx.From, tx.ChainId)
+		if err != nil {
+			return "", err
+		}
+		tx.Nonce = strconv.FormatInt(nonce, 10)
+	}
+	if err := c.thk.SignTransaction(tx, c.privateKey); err != nil {
+		return "", err
+	}
+	return c.thk.SendTx(tx)
+}
@@ -0,0 +1,125 @@
+package thk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ThinkiumGroup/web3.go/web3/dto"
+	"github.com/ThinkiumGroup/web3.go/web3/providers"
+	"strings"
+)
+
+// Classifications of the errors returned by the node, test them with errors.Is
+var (
+	ErrNonceTooLow         = errors.New("nonce too low")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrTxNotFound          = errors.New("transaction not found")
+	ErrChainNotFound       = errors.New("chain not found")
+	ErrNodeError           = errors.New("node error") // not any of the above
+)
+
+// nodeErrorPatterns classifies the node errors by the lower-case substrings of their messages
+var nodeErrorPatterns = []struct {
+	kind     error
+	patterns []string
+}{
+	{ErrNonceTooLow, []string{"nonce too low", "nonce is too low", "nonce too small", "nonce is lower", "nonce is expired"}},
+	{ErrInsufficientBalance, []string{"insufficient balance", "insufficient funds", "not enough balance", "balance not enough", "balance is not enough"}},
+	{ErrChainNotFound, []string{"chain not found", "chain not exist", "chainid not found", "chain id not found", "illegal chainid", "invalid chainid", "no such chain"}},
+	{ErrTxNotFound, []string{"transaction not found", "tx not found", "txhash not found", "receipt not found", "not found the tx"}},
+}
+
+// NodeError is the error returned by the node for an RPC method
+type NodeError struct {
+	Method  string
+	ChainId string // empty if the method is not of a chain
+	Code    int    // code of the JSON-RPC error, 0 for ErrMsg
+	Message string
+	Kind    error // one of ErrNonceTooLow, ErrInsufficientBalance, ErrTxNotFound, ErrChainNotFound or ErrNodeError
+}
+
+// NewNodeError classifies the message of the node
+func NewNodeError(method, chainId string, code int, message string) *NodeError {
+	e := &NodeError{Method: method, ChainId: chainId, Code: code, Message: message, Kind: ErrNodeError}
+	msg := strings.ToLower(message)
+	for _, p := range nodeErrorPatterns {
+		for _, pattern := range p.patterns {
+			if strings.Contains(msg, pattern) {
+				e.Kind = p.kind
+				return e
+			}
+		}
+	}
+	return e
+}
+
+func (e *NodeError) Error() string {
+	s := e.Method
+	if e.ChainId != "" {
+		s += " at chain " + e.ChainId
+	}
+	if e.Code != 0 {
+		return fmt.Sprintf("%s: %s (code %d)", s, e.Message, e.Code)
+	}
+	return s + ": " + e.Message
+}
+
+func (e *NodeError) Unwrap() error {
+	return e.Kind
+}
+
+// nodeResult is the error part of all results, ErrMsg matches both "ErrMsg" and "errMsg"
+type nodeResult struct {
+	ErrMsg string          `json:"ErrMsg"`
+	Error  json.RawMessage `json:"error"`
+}
+
+// send calls the RPC method and decodes the result into res, a *NodeError is returned if the
+// result has ErrMsg or error, or the provider returns the error of the JSON-RPC response
+func (thk *Thk) send(res interface{}, method, chainId string, params interface{}) error {
+	var raw json.RawMessage
+	if err := thk.provider.SendRequest(&raw, method, params); err != nil {
+		var rpcErr *providers.RPCError
+		if errors.As(err, &rpcErr) {
+			return NewNodeError(method, chainId, rpcErr.Code, rpcErr.Message)
+		}
+		return err
+	}
+	if err := checkNodeResult(raw, method, chainId); err != nil {
+		return err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	return json.Unmarshal(raw, res)
+}
+
+func checkNodeResult(raw json.RawMessage, method, chainId string) error {
+	trimmed := strings.TrimSpace(string(raw))
+	if !strings.HasPrefix(trimmed, "{") {
+		return nil
+	}
+	var result nodeResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		// not the type of the result, which is reported when decoding it
+		return nil
+	}
+	if result.ErrMsg != "" {
+		return NewNodeError(method, chainId, 0, result.ErrMsg)
+	}
+	if len(result.Error) == 0 || string(result.Error) == "null" {
+		return nil
+	}
+	var rpcErr dto.Error
+	if err := json.Unmarshal(result.Error, &rpcErr); err == nil {
+		if rpcErr.Code == 0 && rpcErr.Message == "" {
+			return nil
+		}
+		return NewNodeError(method, chainId, rpcErr.Code, rpcErr.Message)
+	}
+	var msg string
+	if err := json.Unmarshal(result.Error, &msg); err == nil && msg != "" {
+		return NewNodeError(method, chainId, 0, msg)
+	}
+	return nil
+}
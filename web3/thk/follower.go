@@ -0,0 +1,187 @@
+package thk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ThinkiumGroup/go-common"
+	"github.com/ThinkiumGroup/web3.go/web3/dto"
+	"strconv"
+	"time"
+)
+
+var ErrReorgTooDeep = errors.New("reorg is deeper than the window of the follower")
+
+// BlockRef is the hash of the block at the height
+type BlockRef struct {
+	Height uint64      `json:"height"`
+	Hash   common.Hash `json:"hash"`
+}
+
+type FollowEventType int
+
+const (
+	FollowNewBlock FollowEventType = iota // a new block is appended to the chain
+	FollowRollback                        // the blocks after Height are replaced, undo them
+	FollowError                           // fetching failed, the follower retries after PollInterval
+)
+
+// FollowEvent is emitted by ChainFollower.Run
+type FollowEvent struct {
+	Type    FollowEventType
+	Height  uint64           // height of the new block, or the common ancestor of a rollback
+	Block   *dto.BlockDetail // the new block
+	Depth   int              // count of the rolled back blocks
+	Removed []BlockRef       // the rolled back blocks in ascending order
+	Err     error
+}
+
+// ChainFollower follows the head of a chain, and keeps the hashes of the recent blocks to detect
+// reorgs by the PreviousHash of the new blocks
+type ChainFollower struct {
+	ChainId      string
+	WindowSize   int           // count of the recent blocks kept, 64 by default
+	PollInterval time.Duration // interval of polling the chain head, 2 seconds by default
+
+	thk    *Thk
+	next   uint64
+	head   uint64
+	window []BlockRef
+}
+
+// NewChainFollower follows the chain from the height. If window is not empty, such as the one saved
+// by the last run, it continues after the last block in window.
+func (thk *Thk) NewChainFollower(chainId string, from uint64, window []BlockRef) *ChainFollower {
+	f := &ChainFollower{
+		ChainId:      chainId,
+		WindowSize:   64,
+		PollInterval: 2 * time.Second,
+		thk:          thk,
+		next:         from,
+		window:       append([]BlockRef(nil), window...),
+	}
+	if len(f.window) > 0 {
+		f.next = f.window[len(f.window)-1].Height + 1
+	}
+	return f
+}
+
+// Window returns the recent blocks followed in ascending order
+func (f *ChainFollower) Window() []BlockRef {
+	return append([]BlockRef(nil), f.window...)
+}
+
+// Run follows the chain until ctx is done or a reorg deeper than the window is detected
+func (f *ChainFollower) Run(ctx context.Context) <-chan FollowEvent {
+	out := make(chan FollowEvent)
+	go func() {
+		defer close(out)
+		for {
+			events, err := f.Poll()
+			if err != nil {
+				events = append(events, FollowEvent{Type: FollowError, Height: f.next, Err: err})
+			}
+			for _, event := range events {
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if errors.Is(err, ErrReorgTooDeep) {
+				return
+			}
+			if len(events) > 0 && err == nil {
+				continue
+			}
+			select {
+			case <-time.After(f.PollInterval):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Poll fetches the next block if the chain has reached it. It returns no event if there's no new
+// block, a new block event, or a rollback event if the block is not the child of the last one.
+func (f *ChainFollower) Poll() ([]FollowEvent, error) {
+	if f.next > f.head {
+		stats, err := f.thk.GetStats(f.ChainId)
+		if err != nil {
+			return nil, err
+		}
+		f.head = uint64(stats.CurrentHeight)
+		if f.next > f.head {
+			return nil, nil
+		}
+	}
+	block, hash, err := f.getBlock(f.next)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.window) > 0 && block.BlockHeader.PreviousHash != f.window[len(f.window)-1].Hash {
+		event, err := f.rollback()
+		if err != nil {
+			return nil, err
+		}
+		return []FollowEvent{*event}, nil
+	}
+	f.window = append(f.window, BlockRef{Height: f.next, Hash: hash})
+	if size := f.windowSize(); len(f.window) > size {
+		f.window = append(f.window[:0], f.window[len(f.window)-size:]...)
+	}
+	f.next++
+	return []FollowEvent{{Type: FollowNewBlock, Height: uint64(block.BlockHeader.Height), Block: block}}, nil
+}
+
+// rollback pops the blocks in window until the canonical child of the last one points to it
+func (f *ChainFollower) rollback() (*FollowEvent, error) {
+	i := len(f.window) - 1
+	for {
+		// the block at window[i] is replaced, check whether its parent is still canonical
+		if i == 0 {
+			return nil, fmt.Errorf("%w: %d blocks replaced since %d", ErrReorgTooDeep, len(f.window), f.window[0].Height)
+		}
+		canonical, _, err := f.getBlock(f.window[i].Height)
+		if err != nil {
+			return nil, err
+		}
+		if canonical.BlockHeader.PreviousHash == f.window[i-1].Hash {
+			break
+		}
+		i--
+	}
+	removed := append([]BlockRef(nil), f.window[i:]...)
+	f.window = f.window[:i]
+	f.next = f.window[i-1].Height + 1
+	return &FollowEvent{
+		Type:    FollowRollback,
+		Height:  f.window[i-1].Height,
+		Depth:   len(removed),
+		Removed: removed,
+	}, nil
+}
+
+func (f *ChainFollower) getBlock(height uint64) (*dto.BlockDetail, common.Hash, error) {
+	block, err := f.thk.GetBlock(f.ChainId, strconv.FormatUint(height, 10))
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	if block.BlockHeader == nil || uint64(block.BlockHeader.Height) != height {
+		return nil, common.Hash{}, fmt.Errorf("block %d is not returned", height)
+	}
+	hash, err := block.BlockHeader.HashValue()
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	return block, common.BytesToHash(hash), nil
+}
+
+func (f *ChainFollower) windowSize() int {
+	if f.WindowSize <= 0 {
+		return 64
+	}
+	return f.WindowSize
+}
@@ -0,0 +1,87 @@
+package thk
+
+import (
+	"errors"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"math/big"
+)
+
+// GasMarginPercent is the extra percentage added to the gas used by CallTransaction in EstimateGas,
+// since the execution path may change between the call and the packing of the transaction
+var GasMarginPercent uint64 = 20
+
+// Errors of EstimateGas and GasPrice if the node doesn't report the value and Thk.DefaultGas is nil
+var (
+	ErrNoGasUsed  = errors.New("no gas used in the result of the call")
+	ErrNoGasPrice = errors.New("no gas price in the stats of the chain")
+)
+
+// EstimateGas executes the transaction by CallTransaction without packing it, and returns the gas
+// used with GasMarginPercent added. The gas parameters in tx.Extra are ignored in the call. If the
+// node doesn't report the gas used, the Gas of Thk.DefaultGas is returned if set, or ErrNoGasUsed.
+func (thk *Thk) EstimateGas(tx *util.Transaction) (uint64, error) {
+	call := *tx
+	call.Extra = ""
+	res, err := thk.CallTransaction(&call)
+	if err != nil {
+		return 0, err
+	}
+	if err := UnpackTxError(res); err != nil {
+		return 0, err
+	}
+	if res.GasUsed <= 0 {
+		if thk.DefaultGas != nil && thk.DefaultGas.Gas > 0 {
+			return thk.DefaultGas.Gas, nil
+		}
+		return 0, ErrNoGasUsed
+	}
+	gas := uint64(res.GasUsed)
+	return gas + gas*GasMarginPercent/100, nil
+}
+
+// GasPrice returns the current gas price of the chain reported by GetStats. If it's not reported,
+// the GasPrice of Thk.DefaultGas is returned if set, or ErrNoGasPrice.
+func (thk *Thk) GasPrice(chainId string) (*big.Int, error) {
+	stats, err := thk.GetStats(chainId)
+	if err != nil {
+		return nil, err
+	}
+	if stats.GasPrice == "" {
+		if thk.DefaultGas != nil && thk.DefaultGas.GasPrice != nil {
+			return new(big.Int).Set(thk.DefaultGas.GasPrice), nil
+		}
+		return nil, ErrNoGasPrice
+	}
+	price, ok := new(big.Int).SetString(stats.GasPrice, 10)
+	if !ok {
+		return nil, errors.New("error gas price: " + stats.GasPrice)
+	}
+	return price, nil
+}
+
+// SuggestGas estimates the gas of the transaction and fetches the gas price of its chain.
+// The max fee to be paid is the Fee() of the returned provider.
+func (thk *Thk) SuggestGas(tx *util.Transaction) (*util.GasProvider, error) {
+	gas, err := thk.EstimateGas(tx)
+	if err != nil {
+		return nil, err
+	}
+	price, err := thk.GasPrice(tx.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	return &util.GasProvider{Gas: gas, GasPrice: price}, nil
+}
+
+// FillGas saves the suggested gas parameters into tx.Extra, and returns the max fee of the
+// transaction. It should be called before SignTransaction.
+func (thk *Thk) FillGas(tx *util.Transaction) (*big.Int, error) {
+	gasProvider, err := thk.SuggestGas(tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.SetGasProvider(gasProvider); err != nil {
+		return nil, err
+	}
+	return gasProvider.Fee(), nil
+}
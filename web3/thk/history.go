@@ -0,0 +1,81 @@
+package thk
+
+import (
+	"errors"
+	"github.com/ThinkiumGroup/web3.go/web3/dto"
+	"strconv"
+	"strings"
+)
+
+// TxDirection is how a transaction relates to an address, the values can be combined as a filter
+type TxDirection int
+
+const (
+	TxIncoming     TxDirection = 1 << iota // transfers received by the address
+	TxOutgoing                             // transfers sent by the address
+	TxContractCall                         // transactions with input, such as contract calls or deployments
+
+	TxAnyDirection = TxIncoming | TxOutgoing | TxContractCall
+)
+
+type HistoryOptions struct {
+	PageHeights uint64      // heights queried by one GetTransactions, 1000 by default
+	Direction   TxDirection // transactions returned, TxAnyDirection by default
+}
+
+var DefaultHistoryOptions = HistoryOptions{
+	PageHeights: 1000,
+	Direction:   TxAnyDirection,
+}
+
+// Direction returns the relations between the transaction and the address. A contract call sent by
+// the address is TxOutgoing|TxContractCall, and a transfer to itself is TxIncoming|TxOutgoing.
+func Direction(address string, tx *dto.GetTransactions) TxDirection {
+	var d TxDirection
+	if strings.EqualFold(tx.From, address) {
+		d |= TxOutgoing
+	}
+	if strings.EqualFold(tx.To, address) {
+		d |= TxIncoming
+	}
+	if tx.Input != "" && tx.Input != "0x" {
+		d |= TxContractCall
+	}
+	return d
+}
+
+// GetAddressHistory returns the transactions of the address from height startHeight to height endHeight
+// (inclusive) in order, the range is split into pages of GetTransactions
+func (thk *Thk) GetAddressHistory(chainId, address string, startHeight, endHeight uint64, opts ...HistoryOptions) ([]dto.GetTransactions, error) {
+	if startHeight > endHeight {
+		return nil, errors.New("start height is greater than end height")
+	}
+	opt := DefaultHistoryOptions
+	if len(opts) > 0 {
+		if opts[0].PageHeights > 0 {
+			opt.PageHeights = opts[0].PageHeights
+		}
+		if opts[0].Direction != 0 {
+			opt.Direction = opts[0].Direction
+		}
+	}
+	var txs []dto.GetTransactions
+	for start := startHeight; ; start += opt.PageHeights {
+		end := endHeight
+		if endHeight-start >= opt.PageHeights {
+			end = start + opt.PageHeights - 1
+		}
+		page, err := thk.GetTransactions(chainId, address, strconv.FormatUint(start, 10), strconv.FormatUint(end, 10))
+		if err != nil {
+			return nil, err
+		}
+		for i := range page {
+			if Direction(address, &page[i])&opt.Direction != 0 {
+				txs = append(txs, page[i])
+			}
+		}
+		if end == endHeight {
+			return txs, nil
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package thk
+
+import (
+	"context"
+	"github.com/ThinkiumGroup/web3.go/web3/dto"
+	"strconv"
+	"time"
+)
+
+// FollowHead as the end height makes the iterators wait for the new blocks of the chain, until the
+// context is done
+const FollowHead = ^uint64(0)
+
+type IterateOptions struct {
+	Concurrency  int           // blocks fetched in parallel, 4 by default
+	PageSize     int           // transactions in a page of GetBlockTxs, 100 by default
+	PollInterval time.Duration // interval of polling the chain head when following it, 2 seconds by default
+}
+
+var DefaultIterateOptions = IterateOptions{
+	Concurrency:  4,
+	PageSize:     100,
+	PollInterval: 2 * time.Second,
+}
+
+func iterateOptions(opts []IterateOptions) IterateOptions {
+	opt := DefaultIterateOptions
+	if len(opts) > 0 {
+		if opts[0].Concurrency > 0 {
+			opt.Concurrency = opts[0].Concurrency
+		}
+		if opts[0].PageSize > 0 {
+			opt.PageSize = opts[0].PageSize
+		}
+		if opts[0].PollInterval > 0 {
+			opt.PollInterval = opts[0].PollInterval
+		}
+	}
+	return opt
+}
+
+// BlockItem is a block yielded by IterateBlocks, the iteration stops after an item with Err
+type BlockItem struct {
+	Height uint64
+	Block  *dto.BlockDetail
+	Err    error
+}
+
+// TxItem is a transaction yielded by IterateTxs, the iteration stops after an item with Err
+type TxItem struct {
+	Height uint64
+	Tx     *dto.TransactionResult
+	Err    error
+}
+
+type fetched struct {
+	height uint64
+	value  interface{}
+	err    error
+}
+
+// IterateBlocks yields the blocks from height from to height to (inclusive) in order, while blocks
+// are prefetched concurrently. The channel is closed when the iteration finishes, fails or ctx is done,
+// so cancel ctx to stop reading early.
+func (thk *Thk) IterateBlocks(ctx context.Context, chainId string, from, to uint64, opts ...IterateOptions) <-chan BlockItem {
+	opt := iterateOptions(opts)
+	results := thk.fetchInOrder(ctx, chainId, from, to, opt, func(height uint64) (interface{}, error) {
+		return thk.GetBlock(chainId, strconv.FormatUint(height, 10))
+	})
+	out := make(chan BlockItem)
+	go func() {
+		defer close(out)
+		for r := range results {
+			item := BlockItem{Height: r.height, Err: r.err}
+			if r.err == nil {
+				item.Block = r.value.(*dto.BlockDetail)
+			}
+			select {
+			case out <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// IterateTxs yields the transactions of the blocks from height from to height to (inclusive) in
+// order. All pages of GetBlockTxs of a block are fetched before yielding its transactions.
+func (thk *Thk) IterateTxs(ctx context.Context, chainId string, from, to uint64, opts ...IterateOptions) <-chan TxItem {
+	opt := iterateOptions(opts)
+	results := thk.fetchInOrder(ctx, chainId, from, to, opt, func(height uint64) (interface{}, error) {
+		return thk.getAllBlockTxs(chainId, height, opt.PageSize)
+	})
+	out := make(chan TxItem)
+	go func() {
+		defer close(out)
+		for r := range results {
+			if r.err != nil {
+				select {
+				case out <- TxItem{Height: r.height, Err: r.err}:
+				case <-ctx.Done():
+				}
+				return
+			}
+			txs := r.value.([]dto.TransactionResult)
+			for i := range txs {
+				select {
+				case out <- TxItem{Height: r.height, Tx: &txs[i]}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// getAllBlockTxs fetches the pages of GetBlockTxs until a page is not full
+func (thk *Thk) getAllBlockTxs(chainId string, height uint64, pageSize int) ([]dto.TransactionResult, error) {
+	var txs []dto.TransactionResult
+	h, size := strconv.FormatUint(height, 10), strconv.Itoa(pageSize)
+	for page := 1; ; page++ {
+		res, err := thk.GetBlockTxs(chainId, h, strconv.Itoa(page), size)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, res.AccountChanges...)
+		if len(res.AccountChanges) < pageSize {
+			return txs, nil
+		}
+	}
+}
+
+// fetchInOrder calls fetch for the heights concurrently, and yields the results in the order of heights.
+// It stops after the first error.
+func (thk *Thk) fetchInOrder(ctx context.Context, chainId string, from, to uint64, opt IterateOptions,
+	fetch func(height uint64) (interface{}, error)) <-chan fetched {
+	ctx, cancel := context.WithCancel(ctx)
+	futures := make(chan chan fetched, opt.Concurrency)
+	sem := make(chan struct{}, opt.Concurrency)
+	go func() {
+		defer close(futures)
+		var head uint64
+		for height := from; height <= to; height++ {
+			if to == FollowHead && !thk.waitHead(ctx, chainId, height, &head, opt.PollInterval) {
+				return
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			future := make(chan fetched, 1)
+			go func(height uint64) {
+				value, err := fetch(height)
+				<-sem
+				future <- fetched{height: height, value: value, err: err}
+			}(height)
+			select {
+			case futures <- future:
+			case <-ctx.Done():
+				return
+			}
+			if height == to {
+				return
+			}
+		}
+	}()
+
+	out := make(chan fetched)
+	go func() {
+		defer cancel()
+		defer close(out)
+		for future := range futures {
+			var r fetched
+			select {
+			case r = <-future:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case out <- r:
+			case <-ctx.Done():
+				return
+			}
+			if r.err != nil {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// waitHead polls GetStats until the chain reaches the height, head caches the last known height
+func (thk *Thk) waitHead(ctx context.Context, chainId string, height uint64, head *uint64, interval time.Duration) bool {
+	for height > *head {
+		if stats, err := thk.GetStats(chainId); err == nil && stats.CurrentHeight > 0 {
+			*head = uint64(stats.CurrentHeight)
+			if height <= *head {
+				return true
+			}
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,214 @@
+package thk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/ThinkiumGroup/go-common"
+	"github.com/ThinkiumGroup/web3.go/common/hexutil"
+	"github.com/ThinkiumGroup/web3.go/web3/dto"
+	"strconv"
+)
+
+var (
+	ErrProofMismatch = errors.New("proof does not match the block header")
+	ErrNoBlockPass   = errors.New("no signature in block pass")
+	ErrNoStorageSlot = errors.New("storage slot is not in the proof")
+)
+
+// TxInclusion is the verified answer that a transaction is packed in a block
+type TxInclusion struct {
+	TxHash     common.Hash
+	Receipt    *dto.TxProof
+	Header     *dto.BlockHeader
+	BlockHash  common.Hash // hash of the header computed locally by BlockHeader.HashValue
+	ProvedRoot string      // which one the proof folds to: "TransactionRoot", "ReceiptRoot" or "BlockHash"
+	Signatures int         // count of the verified signatures in BlockPass, 0 if not checked
+}
+
+// VerifyTxProof fetches the proof of the transaction and the block packing it, and verifies the proof
+// against the block header instead of trusting the node:
+//   - the proof folded from the transaction hash must be the TransactionRoot, ReceiptRoot of the
+//     header, or the hash of the header itself
+//   - the hash of the header is computed locally
+//   - if checkBlockPass, every signature in BlockPass must be valid for the header
+//
+// Whether the signers are the committee of the chain is checked by the light client.
+func (thk *Thk) VerifyTxProof(chainId string, hash string, checkBlockPass bool) (*TxInclusion, error) {
+	txHash, err := hexutil.Decode(hash)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := thk.GetTxProof(chainId, hash)
+	if err != nil {
+		return nil, err
+	}
+	if proof.TxReceipt.TxHash != (common.Hash{}) && !bytes.Equal(proof.TxReceipt.TxHash[:], txHash) {
+		return nil, fmt.Errorf("proof of %x is returned for %s", proof.TxReceipt.TxHash[:], hash)
+	}
+	block, err := thk.GetBlock(chainId, strconv.FormatUint(uint64(proof.TxReceipt.Height), 10))
+	if err != nil {
+		return nil, err
+	}
+	if block.BlockHeader == nil {
+		return nil, fmt.Errorf("no header of block %d", proof.TxReceipt.Height)
+	}
+	inclusion, err := VerifyTxInclusion(txHash, proof, block.BlockHeader)
+	if err != nil {
+		return nil, err
+	}
+	if checkBlockPass {
+		if inclusion.Signatures, err = VerifyBlockPass(block.BlockHeader, block.BlockPass); err != nil {
+			return nil, err
+		}
+	}
+	return inclusion, nil
+}
+
+// VerifyTxInclusion checks that the proof folded from the transaction hash matches the header
+func VerifyTxInclusion(txHash []byte, proof *dto.TxProof, header *dto.BlockHeader) (*TxInclusion, error) {
+	if len(proof.Proof) == 0 {
+		return nil, errors.New("empty proof")
+	}
+	if header.Height != proof.TxReceipt.Height {
+		return nil, fmt.Errorf("transaction is packed at %d, but header is at %d", proof.TxReceipt.Height, header.Height)
+	}
+	blockHash, err := header.HashValue()
+	if err != nil {
+		return nil, err
+	}
+	root, err := proof.Proof.Proof(txHash)
+	if err != nil {
+		return nil, err
+	}
+	inclusion := &TxInclusion{
+		TxHash:    common.BytesToHash(txHash),
+		Receipt:   proof,
+		Header:    header,
+		BlockHash: common.BytesToHash(blockHash),
+	}
+	switch {
+	case header.TransactionRoot != nil && bytes.Equal(root, header.TransactionRoot[:]):
+		inclusion.ProvedRoot = "TransactionRoot"
+	case header.ReceiptRoot != nil && bytes.Equal(root, header.ReceiptRoot[:]):
+		inclusion.ProvedRoot = "ReceiptRoot"
+	case bytes.Equal(root, blockHash):
+		inclusion.ProvedRoot = "BlockHash"
+	default:
+		return nil, fmt.Errorf("%w: proof of %x folds to %x at height %d", ErrProofMismatch, txHash, root, header.Height)
+	}
+	return inclusion, nil
+}
+
+// VerifyBlockPass checks every signature of the block, and returns the count of them
+func VerifyBlockPass(header *dto.BlockHeader, pass dto.PubAndSigs) (int, error) {
+	count := 0
+	for i, pas := range pass {
+		if pas == nil {
+			continue
+		}
+		if !common.VerifyMsg(header, pas.PublicKey.Bytes(), pas.Signature.Bytes()) {
+			return count, fmt.Errorf("invalid signature %d of block %d by %x", i, header.Height, pas.PublicKey[:])
+		}
+		count++
+	}
+	if count == 0 {
+		return 0, ErrNoBlockPass
+	}
+	return count, nil
+}
+
+// VerifiedAccount gets the account at the height of the header, which should be verified by the
+// light client, and verifies the account and the storage slots against the StateRoot of the header
+func (thk *Thk) VerifiedAccount(address string, header *dto.BlockHeader, storageKeys ...string) (*dto.AccountProof, error) {
+	chainId := strconv.FormatUint(uint64(header.ChainID), 10)
+	height := strconv.FormatUint(uint64(header.Height), 10)
+	proof, err := thk.GetAccountProof(address, chainId, height, storageKeys...)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := hexutil.Decode(address)
+	if err != nil {
+		return nil, err
+	}
+	if proof.Account == nil || !bytes.Equal(proof.Account.Addr[:], addr) {
+		return nil, fmt.Errorf("account %s is not returned", address)
+	}
+	if err = VerifyAccountProof(proof, header); err != nil {
+		return nil, err
+	}
+	for _, key := range storageKeys {
+		k, err := hexutil.Decode(key)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = StorageValue(proof, k); err != nil {
+			return nil, err
+		}
+	}
+	return proof, nil
+}
+
+// StorageValue returns the value of the storage slot in the proof, which should be verified
+func StorageValue(proof *dto.AccountProof, key []byte) ([]byte, error) {
+	for _, storage := range proof.Storage {
+		if storage != nil && bytes.Equal(storage.Key, key) {
+			return storage.Value, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %x", ErrNoStorageSlot, key)
+}
+
+// VerifyAccountProof checks that the account folds to the StateRoot of the header, and that all
+// storage slots in the proof fold to the StorageRoot of the account
+func VerifyAccountProof(proof *dto.AccountProof, header *dto.BlockHeader) error {
+	if proof.Account == nil {
+		return errors.New("no account in proof")
+	}
+	if proof.Height != header.Height {
+		return fmt.Errorf("account is proved at %d, but header is at %d", proof.Height, header.Height)
+	}
+	accountHash, err := common.HashObject(proof.Account)
+	if err != nil {
+		return err
+	}
+	root, err := proof.Proof.Proof(accountHash)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(root, header.StateRoot[:]) {
+		return fmt.Errorf("%w: account %x folds to %x, state root %x", ErrProofMismatch, proof.Account.Addr[:], root, header.StateRoot[:])
+	}
+	for _, storage := range proof.Storage {
+		if err = VerifyStorageProof(proof.Account, storage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyStorageProof checks that the path of the proof is the key of the storage slot, and that
+// the value of the slot folds to the StorageRoot of the account
+func VerifyStorageProof(account *dto.AccountState, storage *dto.StorageProof) error {
+	if storage == nil {
+		return errors.New("nil storage proof")
+	}
+	if len(account.StorageRoot) == 0 {
+		return fmt.Errorf("account %x has no storage", account.Addr[:])
+	}
+	key, err := storage.Proof.Key()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(key, storage.Key) {
+		return fmt.Errorf("%w: storage %x of %x is proved by the path of %x", ErrProofMismatch, []byte(storage.Key), account.Addr[:], key)
+	}
+	root, err := storage.Proof.Proof(storage.Value)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(root, account.StorageRoot) {
+		return fmt.Errorf("%w: storage %x of %x folds to %x, storage root %x", ErrProofMismatch, []byte(storage.Key), account.Addr[:], root, account.StorageRoot)
+	}
+	return nil
+}
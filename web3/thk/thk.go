@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/ThinkiumGroup/web3.go/common"
 	"github.com/ThinkiumGroup/web3.go/common/hexutil"
+	"github.com/ThinkiumGroup/web3.go/common/units"
 	"github.com/ThinkiumGroup/web3.go/web3/dto"
 	"github.com/ThinkiumGroup/web3.go/web3/providers"
 	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
@@ -17,6 +18,10 @@ type Thk struct {
 	DefaultExtraPrivateKeys []string
 	DefaultAuthKey          string
 	DefaultChainId          string
+	Network                 *util.Network // network of the transactions signed by SignTransaction
+	// gas parameters used by EstimateGas and GasPrice if the node doesn't report them, such as
+	// util.DefaultGasProvider, nil to return the errors
+	DefaultGas *util.GasProvider
 
 	provider providers.ProviderInterface
 }
@@ -24,10 +29,15 @@ type Thk struct {
 func NewThk(provider providers.ProviderInterface) *Thk {
 	thk := new(Thk)
 	thk.provider = provider
+	thk.Network = util.DefaultNetwork()
 	return thk
 }
-func SetBaseChainId(baseChainId int64) {
-	util.BaseChainId = baseChainId
+
+// NewThkOnNetwork returns the Thk signing the transactions on the network
+func NewThkOnNetwork(provider providers.ProviderInterface, network *util.Network) *Thk {
+	thk := NewThk(provider)
+	thk.Network = network
+	return thk
 }
 
 func (thk *Thk) GetAccount(address string, chainId string) (*util.Account, error) {
@@ -36,15 +46,28 @@ func (thk *Thk) GetAccount(address string, chainId string) (*util.Account, error
 		ChainId: chainId,
 	}
 	res := util.Account{}
-	if err := thk.provider.SendRequest(&res, "GetAccount", params); err != nil {
+	if err := thk.send(&res, "GetAccount", chainId, params); err != nil {
 		return nil, err
 	}
-	if res.ErrMsg != "" {
-		return nil, errors.New(res.ErrMsg)
-	}
 	return &res, nil
 }
 
+// GetAccountProof gets the account at the height with the proofs of the account and the storage
+// slots, which can be verified by VerifyAccountProof against the StateRoot of the block header
+func (thk *Thk) GetAccountProof(address string, chainId string, height string, storageKeys ...string) (*dto.AccountProof, error) {
+	params := util.GetAccountProofJson{
+		Address:     address,
+		ChainId:     chainId,
+		Height:      height,
+		StorageKeys: storageKeys,
+	}
+	res := new(dto.AccountProof)
+	if err := thk.send(res, "GetAccountProof", chainId, params); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 func (thk *Thk) GetBalance(address string, chainId string) (*big.Int, error) {
 	res, err := thk.GetAccount(address, chainId)
 	if err != nil {
@@ -54,6 +77,15 @@ func (thk *Thk) GetBalance(address string, chainId string) (*big.Int, error) {
 	return ret, nil
 }
 
+// GetFormattedBalance returns the balance in the unit, such as "1.5 TKM" in units.TKM
+func (thk *Thk) GetFormattedBalance(address string, chainId string, unit units.Unit) (string, error) {
+	balance, err := thk.GetBalance(address, chainId)
+	if err != nil {
+		return "", err
+	}
+	return units.FormatWithUnit(balance, unit), nil
+}
+
 func (thk *Thk) GetNonce(address string, chainId string) (int64, error) {
 	res, err := thk.GetAccount(address, chainId)
 	if err != nil {
@@ -70,29 +102,22 @@ func (thk *Thk) GetBlockTxs(chainId string, height string, page string, size str
 		Size:    size,
 	}
 	var res dto.BlockTxs
-	if err := thk.provider.SendRequest(&res, "GetBlockTxs", params); err != nil {
+	if err := thk.send(&res, "GetBlockTxs", chainId, params); err != nil {
 		return nil, err
 	}
-	if res.ErrMsg != "" {
-		return nil, errors.New(res.ErrMsg)
-	}
 	return &res, nil
 }
 
 func (thk *Thk) SendTx(transaction *util.Transaction) (string, error) {
 	res := new(dto.SendTxResult)
-	if err := thk.provider.SendRequest(res, "SendTx", transaction); err != nil {
-		return "", err
-	}
-	if res.ErrMsg != "" {
-		err := errors.New(res.ErrMsg)
+	if err := thk.send(res, "SendTx", transaction.ChainId, transaction); err != nil {
 		return "", err
 	}
 	return res.TXhash, nil
 }
 
 func (thk *Thk) SignTransaction(transaction *util.Transaction, privateKey string, multikeys ...string) error {
-	hash, err := transaction.HashValue()
+	hash, err := transaction.HashValue(thk.Network)
 	if err != nil {
 		return err
 	}
@@ -128,11 +153,7 @@ func (thk *Thk) SignTransaction(transaction *util.Transaction, privateKey string
 
 func (thk *Thk) CallTransaction(transaction *util.Transaction) (*dto.TxResult, error) {
 	res := new(dto.TxResult)
-	if err := thk.provider.SendRequest(res, "CallTransaction", transaction); err != nil {
-		return nil, err
-	}
-	if res.ErrMsg != "" {
-		err := errors.New(res.ErrMsg)
+	if err := thk.send(res, "CallTransaction", transaction.ChainId, transaction); err != nil {
 		return nil, err
 	}
 	return res, nil
@@ -144,11 +165,7 @@ func (thk *Thk) GetTransactionByHash(chainId string, hash string) (*dto.TxResult
 		Hash:    hash,
 	}
 	res := new(dto.TxResult)
-	if err := thk.provider.SendRequest(res, "GetTransactionByHash", params); err != nil {
-		return nil, err
-	}
-	if res.ErrMsg != "" {
-		err := errors.New(res.ErrMsg)
+	if err := thk.send(res, "GetTransactionByHash", chainId, params); err != nil {
 		return nil, err
 	}
 	return res, nil
@@ -160,11 +177,7 @@ func (thk *Thk) GetTxProof(chainId string, hash string) (*dto.TxProof, error) {
 		Hash:    hash,
 	}
 	res := new(dto.TxProof)
-	if err := thk.provider.SendRequest(res, "GetTxProof", params); err != nil {
-		return nil, err
-	}
-	if res.ErrMsg != "" {
-		err := errors.New(res.ErrMsg)
+	if err := thk.send(res, "GetTxProof", chainId, params); err != nil {
 		return nil, err
 	}
 	return res, nil
@@ -176,14 +189,31 @@ func (thk *Thk) GetBlockHeader(chainId string, height string) (*dto.GetBlockResu
 		Height:  height,
 	}
 	res := new(dto.GetBlockResult)
-	if err := thk.provider.SendRequest(res, "GetBlockHeader", params); err != nil {
+	if err := thk.send(res, "GetBlockHeader", chainId, params); err != nil {
 		return nil, err
 	}
-	if res.ErrMsg != "" {
-		err := errors.New(res.ErrMsg)
+	return res, nil
+}
+
+// GetTypedBlockHeader gets the full header of the block, and checks it against the summary and
+// the hash reported by GetBlockHeader. An error of *dto.HeaderMismatchError is returned if they
+// are not consistent.
+func (thk *Thk) GetTypedBlockHeader(chainId string, height string) (*dto.BlockHeader, error) {
+	summary, err := thk.GetBlockHeader(chainId, height)
+	if err != nil {
 		return nil, err
 	}
-	return res, nil
+	block, err := thk.GetBlock(chainId, height)
+	if err != nil {
+		return nil, err
+	}
+	if block.BlockHeader == nil {
+		return nil, fmt.Errorf("no header of block %s at chain %s", height, chainId)
+	}
+	if err = summary.Verify(block.BlockHeader); err != nil {
+		return nil, err
+	}
+	return block.BlockHeader, nil
 }
 
 func (thk *Thk) GetBlock(chainId string, height string) (*dto.BlockDetail, error) {
@@ -192,11 +222,7 @@ func (thk *Thk) GetBlock(chainId string, height string) (*dto.BlockDetail, error
 		Height:  height,
 	}
 	var res dto.BlockDetail
-	if err := thk.provider.SendRequest(&res, "GetBlock", params); err != nil {
-		return nil, err
-	}
-	if res.ErrMsg != "" {
-		err := errors.New(res.ErrMsg)
+	if err := thk.send(&res, "GetBlock", chainId, params); err != nil {
 		return nil, err
 	}
 	return &res, nil
@@ -208,13 +234,9 @@ func (thk *Thk) Ping(address string) (*dto.NodeInfo, error) {
 		Address: address,
 	}
 	res := new(dto.NodeInfo)
-	if err := thk.provider.SendRequest(&res, "/chaininfo:Ping", params); err != nil {
+	if err := thk.send(res, "/chaininfo:Ping", "", params); err != nil {
 		return nil, err
 	}
-
-	if res.ErrMsg != "" {
-		return nil, errors.New(res.ErrMsg)
-	}
 	return res, nil
 }
 
@@ -222,7 +244,7 @@ func (thk *Thk) GetChainInfo(chainIds []int) ([]dto.GetChainInfo, error) {
 	params := new(util.GetChainInfoJson)
 	params.ChainIds = chainIds
 	var resArray []dto.GetChainInfo
-	if err := thk.provider.SendRequest(&resArray, "/chaininfo:GetChainInfo", params); err != nil {
+	if err := thk.send(&resArray, "/chaininfo:GetChainInfo", "", params); err != nil {
 		return nil, err
 	}
 	return resArray, nil
@@ -232,13 +254,13 @@ func (thk *Thk) GetStats(chainId string) (gts dto.GetChainStats, err error) {
 	params := new(util.GetStatsJson)
 	params.ChainId = chainId
 	res := new(dto.GetChainStats)
-	if err := thk.provider.SendRequest(&res, "GetStats", params); err != nil {
+	if err := thk.send(res, "GetStats", chainId, params); err != nil {
 		return *res, err
 	}
 	return *res, nil
 }
 
-// GetTransactions
+// GetTransactions returns the transactions sent or received by the address between the heights
 func (thk *Thk) GetTransactions(chainId, address, startHeight, endHeight string) ([]dto.GetTransactions, error) {
 	params := util.GetTransactionsJson{
 		ChainId:     chainId,
@@ -247,12 +269,10 @@ func (thk *Thk) GetTransactions(chainId, address, startHeight, endHeight string)
 		EndHeight:   endHeight,
 	}
 
-	res := new(dto.GetTransactions)
-	if err := thk.provider.SendRequest(res, "GetTransactions", params); err != nil {
+	var resArray []dto.GetTransactions
+	if err := thk.send(&resArray, "GetTransactions", chainId, params); err != nil {
 		return nil, err
 	}
-
-	resArray := []dto.GetTransactions{*res}
 	return resArray, nil
 }
 
@@ -262,40 +282,48 @@ func (thk *Thk) GetCommittee(chainId string, epoch string) ([]string, error) {
 		Epoch:   epoch,
 	}
 	var res []string
-	if err := thk.provider.SendRequest(&res, "/chaininfo:GetCommittee", params); err != nil {
+	if err := thk.send(&res, "/chaininfo:GetCommittee", chainId, params); err != nil {
 		return nil, err
 	}
 	return res, nil
 }
 
-func (thk *Thk) RpcMakeVccProof(cashCheque *CashCheque) (map[string]interface{}, error) {
-	res := make(map[string]interface{})
-	if err := thk.provider.SendRequest(&res, "RpcMakeVccProof", &cashCheque); err != nil {
+func (thk *Thk) RpcMakeVccProof(cashCheque *CashCheque) (*VccProof, error) {
+	res := new(VccProof)
+	if err := thk.send(res, "RpcMakeVccProof", cashCheque.FromChainId, cashCheque); err != nil {
+		return nil, err
+	}
+	if err := checkChequeProof(&res.ChequeProof, "RpcMakeVccProof", cashCheque.FromChainId); err != nil {
 		return nil, err
 	}
 	return res, nil
 }
 
-func (thk *Thk) MakeCCCExistenceProof(cashCheque *CashCheque) (map[string]interface{}, error) {
-	res := make(map[string]interface{})
-	if err := thk.provider.SendRequest(&res, "MakeCCCExistenceProof", cashCheque); err != nil {
+func (thk *Thk) MakeCCCExistenceProof(cashCheque *CashCheque) (*CancelProof, error) {
+	res := new(CancelProof)
+	if err := thk.send(res, "MakeCCCExistenceProof", cashCheque.ToChainId, cashCheque); err != nil {
 		return nil, err
 	}
-	if res["errMsg"] != nil && res["errMsg"].(string) != "" {
-		err := errors.New(res["errMsg"].(string))
+	if res.Existence && res.ErrMsg == "" && res.ErrCode == 0 {
+		// no input is generated for a cashed cheque
+		return res, nil
+	}
+	if err := checkChequeProof(&res.ChequeProof, "MakeCCCExistenceProof", cashCheque.ToChainId); err != nil {
 		return nil, err
 	}
 	return res, nil
 }
 
 // GetCCCRelativeTx
-func (thk *Thk) GetCCCRelativeTx(transaction *util.Transaction) (map[string]interface{}, error) {
+func (thk *Thk) GetCCCRelativeTx(transaction *util.Transaction) (*dto.ChequeProof, error) {
 	res := new(dto.GetCCCRelativeTxJson)
-	if err := thk.provider.SendRequest(res, "GetCCCRelativeTx", transaction); err != nil {
+	if err := thk.send(res, "GetCCCRelativeTx", transaction.ChainId, transaction); err != nil {
 		return nil, err
 	}
-	if res.ErrMsg != "" {
-		err := errors.New(res.ErrMsg)
+	if res.Proof == nil {
+		return nil, errors.New("no proof in result")
+	}
+	if err := checkChequeProof(res.Proof, "GetCCCRelativeTx", transaction.ChainId); err != nil {
 		return nil, err
 	}
 	return res.Proof, nil
@@ -0,0 +1,126 @@
+package thk
+
+import (
+	"fmt"
+	"github.com/ThinkiumGroup/web3.go/common"
+	"github.com/ThinkiumGroup/web3.go/common/hexutil"
+	"github.com/ThinkiumGroup/web3.go/web3/dto"
+	"github.com/ThinkiumGroup/web3.go/web3/thk/util"
+	"math/big"
+	"strconv"
+)
+
+// TypedThk has the methods of Thk taking and returning the typed values instead of strings, which are
+// only formatted when sent to the node
+type TypedThk struct {
+	thk *Thk
+}
+
+// Typed returns the typed API of thk
+func (thk *Thk) Typed() *TypedThk {
+	return &TypedThk{thk: thk}
+}
+
+func chainIdString(chainId common.ChainId) string {
+	return strconv.FormatUint(uint64(chainId), 10)
+}
+
+func heightString(height common.Height) string {
+	return strconv.FormatUint(uint64(height), 10)
+}
+
+func addressString(address common.Address) string {
+	return hexutil.Encode(address[:])
+}
+
+func (t *TypedThk) GetAccount(address common.Address, chainId common.ChainId) (*util.Account, error) {
+	return t.thk.GetAccount(addressString(address), chainIdString(chainId))
+}
+
+func (t *TypedThk) GetBalance(address common.Address, chainId common.ChainId) (*big.Int, error) {
+	return t.thk.GetBalance(addressString(address), chainIdString(chainId))
+}
+
+func (t *TypedThk) GetNonce(address common.Address, chainId common.ChainId) (uint64, error) {
+	account, err := t.GetAccount(address, chainId)
+	if err != nil {
+		return 0, err
+	}
+	return account.Nonce, nil
+}
+
+func (t *TypedThk) GetAccountProof(address common.Address, chainId common.ChainId, height common.Height, storageKeys ...common.Hash) (*dto.AccountProof, error) {
+	keys := make([]string, len(storageKeys))
+	for i := range storageKeys {
+		keys[i] = storageKeys[i].Hex()
+	}
+	return t.thk.GetAccountProof(addressString(address), chainIdString(chainId), heightString(height), keys...)
+}
+
+func (t *TypedThk) GetBlock(chainId common.ChainId, height common.Height) (*dto.BlockDetail, error) {
+	return t.thk.GetBlock(chainIdString(chainId), heightString(height))
+}
+
+func (t *TypedThk) GetBlockHeader(chainId common.ChainId, height common.Height) (*dto.BlockHeader, error) {
+	return t.thk.GetTypedBlockHeader(chainIdString(chainId), heightString(height))
+}
+
+func (t *TypedThk) GetBlockTxs(chainId common.ChainId, height common.Height, page, size int) (*dto.BlockTxs, error) {
+	return t.thk.GetBlockTxs(chainIdString(chainId), heightString(height), strconv.Itoa(page), strconv.Itoa(size))
+}
+
+func (t *TypedThk) GetTransactionByHash(chainId common.ChainId, hash common.Hash) (*dto.TxResult, error) {
+	return t.thk.GetTransactionByHash(chainIdString(chainId), hash.Hex())
+}
+
+func (t *TypedThk) GetTxProof(chainId common.ChainId, hash common.Hash) (*dto.TxProof, error) {
+	return t.thk.GetTxProof(chainIdString(chainId), hash.Hex())
+}
+
+func (t *TypedThk) GetTransactions(chainId common.ChainId, address common.Address, startHeight, endHeight common.Height) ([]dto.GetTransactions, error) {
+	return t.thk.GetTransactions(chainIdString(chainId), addressString(address), heightString(startHeight), heightString(endHeight))
+}
+
+func (t *TypedThk) GetStats(chainId common.ChainId) (dto.GetChainStats, error) {
+	return t.thk.GetStats(chainIdString(chainId))
+}
+
+func (t *TypedThk) GetCommittee(chainId common.ChainId, epoch uint64) ([]string, error) {
+	return t.thk.GetCommittee(chainIdString(chainId), strconv.FormatUint(epoch, 10))
+}
+
+func (t *TypedThk) CallTransaction(tx *util.TypedTransaction) (*dto.TxResult, error) {
+	transaction, err := tx.Transaction()
+	if err != nil {
+		return nil, err
+	}
+	return t.thk.CallTransaction(transaction)
+}
+
+func (t *TypedThk) EstimateGas(tx *util.TypedTransaction) (uint64, error) {
+	transaction, err := tx.Transaction()
+	if err != nil {
+		return 0, err
+	}
+	return t.thk.EstimateGas(transaction)
+}
+
+// SendTransaction signs the transaction by the private key and sends it, returns the transaction hash
+func (t *TypedThk) SendTransaction(tx *util.TypedTransaction, privateKey string, multikeys ...string) (common.Hash, error) {
+	transaction, err := tx.Transaction()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err = t.thk.SignTransaction(transaction, privateKey, multikeys...); err != nil {
+		return common.Hash{}, err
+	}
+	hash, err := t.thk.SendTx(transaction)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	b, err := hexutil.Decode(hash)
+	if err != nil || len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid transaction hash %q", hash)
+	}
+	return common.BytesToHash(b), nil
+}
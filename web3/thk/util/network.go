@@ -0,0 +1,72 @@
+package util
+
+import (
+	"fmt"
+	"github.com/ThinkiumGroup/web3.go/common"
+	"math/big"
+	"strconv"
+)
+
+// DefaultBaseChainId is the base chain id of DefaultNetwork
+const DefaultBaseChainId int64 = 100007
+
+// SystemContracts are the addresses of the system contracts of the cross chain cheques
+type SystemContracts struct {
+	Withdraw string // writes the cheque on the from chain
+	Deposit  string // cashes the cheque on the to chain
+	Cancel   string // cancels the cheque on the from chain
+}
+
+var DefaultSystemContracts = SystemContracts{
+	Withdraw: "0x0000000000000000000000000000000000020000",
+	Deposit:  "0x0000000000000000000000000000000000030000",
+	Cancel:   "0x0000000000000000000000000000000000040000",
+}
+
+// Network describes a Thinkium network, such as the mainnet or a testnet. Each Thk has its own
+// Network, so that the clients of different networks can be used in one process.
+type Network struct {
+	Name            string
+	BaseChainId     int64 // added to the chain id of the transactions when hashing
+	SystemContracts SystemContracts
+	Chains          []common.ChainId // known chains of the network, any chain is accepted if empty
+}
+
+func NewNetwork(name string, baseChainId int64) *Network {
+	return &Network{Name: name, BaseChainId: baseChainId, SystemContracts: DefaultSystemContracts}
+}
+
+// DefaultNetwork returns a new Network with DefaultBaseChainId and DefaultSystemContracts
+func DefaultNetwork() *Network {
+	return NewNetwork("default", DefaultBaseChainId)
+}
+
+// HasChain reports whether the chain is a known chain of the network
+func (n *Network) HasChain(chainId common.ChainId) bool {
+	if len(n.Chains) == 0 {
+		return true
+	}
+	for _, id := range n.Chains {
+		if id == chainId {
+			return true
+		}
+	}
+	return false
+}
+
+// SigningChainId returns the chain id in the hash of a transaction of the chain, that's the chain
+// id plus BaseChainId
+func (n *Network) SigningChainId(chainId string) (*big.Int, error) {
+	id, err := strconv.ParseUint(chainId, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("error chainId %q", chainId)
+	}
+	if !n.HasChain(common.ChainId(id)) {
+		return nil, fmt.Errorf("chain %d is not a chain of network %s", id, n.Name)
+	}
+	return new(big.Int).Add(new(big.Int).SetUint64(id), big.NewInt(n.BaseChainId)), nil
+}
+
+func (n *Network) String() string {
+	return fmt.Sprintf("%s(base chain id %d)", n.Name, n.BaseChainId)
+}
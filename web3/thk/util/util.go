@@ -3,8 +3,10 @@ package util
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	common2 "github.com/ThinkiumGroup/go-common"
 	"github.com/ThinkiumGroup/web3.go/common"
+	"github.com/ThinkiumGroup/web3.go/common/hexutil"
 	"math/big"
 	"strconv"
 	"strings"
@@ -17,6 +19,13 @@ type GetAccountJson struct {
 	ChainId string `json:"chainId"`
 }
 
+type GetAccountProofJson struct {
+	Address     string   `json:"address"`
+	ChainId     string   `json:"chainId"`
+	Height      string   `json:"height"`
+	StorageKeys []string `json:"storageKeys,omitempty"`
+}
+
 type GetBlockTxsJson struct {
 	ChainId string `json:"chainId"`
 	Height  string `json:"height"`
@@ -64,21 +73,16 @@ var DefaultGasProvider = &GasProvider{
 	GasPrice: big.NewInt(40 * 10000 * 10000),
 }
 
-var BaseChainId int64 = 100007
-
-func (tx *Transaction) HashValue() ([]byte, error) {
-	chainId, ok := new(big.Int).SetString(tx.ChainId, 10)
-	if !ok {
-		return nil, errors.New("error chainId")
-	}
-	chainId.Add(chainId, big.NewInt(BaseChainId))
-	if tx.Value == "" {
-		tx.Value = "0"
-	}
-	value, ok := new(big.Int).SetString(tx.Value, 10)
-	if !ok {
-		return nil, errors.New("error value")
+// Fee returns the max fee of the transaction: Gas * GasPrice
+func (g *GasProvider) Fee() *big.Int {
+	if g == nil || g.GasPrice == nil {
+		return big.NewInt(0)
 	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(g.Gas), g.GasPrice)
+}
+
+// GasProvider decodes the gas parameters saved in Extra, GasPrice is zero if not set
+func (tx *Transaction) GasProvider() (*GasProvider, error) {
 	var gasProvider GasProvider
 	gasBytes := common.FromHex(tx.Extra)
 	if len(gasBytes) > 0 {
@@ -90,6 +94,39 @@ func (tx *Transaction) HashValue() ([]byte, error) {
 	if gasProvider.GasPrice == nil {
 		gasProvider.GasPrice = big.NewInt(0)
 	}
+	return &gasProvider, nil
+}
+
+// SetGasProvider saves the gas parameters into Extra, it should be called before signing
+func (tx *Transaction) SetGasProvider(gasProvider *GasProvider) error {
+	gasBytes, err := json.Marshal(gasProvider)
+	if err != nil {
+		return err
+	}
+	tx.Extra = hexutil.Encode(gasBytes)
+	return nil
+}
+
+// HashValue returns the hash of the transaction signed on the network, DefaultNetwork if nil
+func (tx *Transaction) HashValue(network *Network) ([]byte, error) {
+	if network == nil {
+		network = DefaultNetwork()
+	}
+	chainId, err := network.SigningChainId(tx.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Value == "" {
+		tx.Value = "0"
+	}
+	value, ok := new(big.Int).SetString(tx.Value, 10)
+	if !ok {
+		return nil, errors.New("error value")
+	}
+	gasProvider, err := tx.GasProvider()
+	if err != nil {
+		return nil, err
+	}
 	nonce, err := strconv.ParseInt(tx.Nonce, 10, 64)
 	if err != nil {
 		return nil, err
@@ -106,6 +143,143 @@ func (tx *Transaction) HashValue() ([]byte, error) {
 	return hash.Bytes(), nil
 }
 
+// TypedTransaction is the Transaction with parsed fields, it's converted to the string based
+// Transaction only when sent to the node
+type TypedTransaction struct {
+	ChainId      common.ChainId
+	FromChainId  *common.ChainId // ChainId if nil
+	ToChainId    *common.ChainId // ChainId if nil
+	From         common.Address
+	To           *common.Address // nil for contract deployment
+	Nonce        uint64
+	Value        *big.Int // 0 if nil
+	Input        []byte
+	UseLocal     bool
+	Gas          *GasProvider // saved in Extra if not nil
+	ExpireHeight common.Height
+}
+
+// Transaction converts the transaction to the wire format
+func (tx *TypedTransaction) Transaction() (*Transaction, error) {
+	if tx.Value != nil && tx.Value.Sign() < 0 {
+		return nil, errors.New("negative value")
+	}
+	fromChainId, toChainId := tx.ChainId, tx.ChainId
+	if tx.FromChainId != nil {
+		fromChainId = *tx.FromChainId
+	}
+	if tx.ToChainId != nil {
+		toChainId = *tx.ToChainId
+	}
+	t := &Transaction{
+		ChainId:      strconv.FormatUint(uint64(tx.ChainId), 10),
+		FromChainId:  strconv.FormatUint(uint64(fromChainId), 10),
+		ToChainId:    strconv.FormatUint(uint64(toChainId), 10),
+		From:         hexutil.Encode(tx.From[:]),
+		Nonce:        strconv.FormatUint(tx.Nonce, 10),
+		Value:        "0",
+		UseLocal:     tx.UseLocal,
+		ExpireHeight: int64(tx.ExpireHeight),
+	}
+	if tx.To != nil {
+		t.To = hexutil.Encode(tx.To[:])
+	}
+	if tx.Value != nil {
+		t.Value = tx.Value.String()
+	}
+	if len(tx.Input) > 0 {
+		t.Input = hexutil.Encode(tx.Input)
+	}
+	if tx.Gas != nil {
+		if err := t.SetGasProvider(tx.Gas); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// Typed parses the fields of the transaction, it fails on the first malformed one
+func (tx *Transaction) Typed() (*TypedTransaction, error) {
+	chainId, err := parseChainId("chainId", tx.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	t := &TypedTransaction{ChainId: chainId, UseLocal: tx.UseLocal, ExpireHeight: common.Height(tx.ExpireHeight)}
+	if t.FromChainId, err = parseOptionalChainId("fromChainId", tx.FromChainId); err != nil {
+		return nil, err
+	}
+	if t.ToChainId, err = parseOptionalChainId("toChainId", tx.ToChainId); err != nil {
+		return nil, err
+	}
+	if t.From, err = parseAddress("from", tx.From); err != nil {
+		return nil, err
+	}
+	if tx.To != "" {
+		to, err := parseAddress("to", tx.To)
+		if err != nil {
+			return nil, err
+		}
+		t.To = &to
+	}
+	if tx.Nonce != "" {
+		if t.Nonce, err = strconv.ParseUint(tx.Nonce, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid nonce %q: %v", tx.Nonce, err)
+		}
+	}
+	t.Value = big.NewInt(0)
+	if tx.Value != "" {
+		if _, ok := t.Value.SetString(tx.Value, 10); !ok || t.Value.Sign() < 0 {
+			return nil, fmt.Errorf("invalid value %q", tx.Value)
+		}
+	}
+	if tx.Input != "" {
+		if t.Input, err = hexutil.Decode(tx.Input); err != nil {
+			return nil, fmt.Errorf("invalid input: %v", err)
+		}
+	}
+	if tx.Extra != "" {
+		if t.Gas, err = tx.GasProvider(); err != nil {
+			return nil, fmt.Errorf("invalid extra: %v", err)
+		}
+	}
+	return t, nil
+}
+
+func parseChainId(name, s string) (common.ChainId, error) {
+	id, err := strconv.ParseUint(s, 10, 32)
+	if err != nil || uint32(id) >= common.ReservedMaxChainID {
+		return 0, fmt.Errorf("invalid %s %q", name, s)
+	}
+	return common.ChainId(id), nil
+}
+
+// parseOptionalChainId returns nil if the chain id is not set
+func parseOptionalChainId(name, s string) (*common.ChainId, error) {
+	if s == "" {
+		return nil, nil
+	}
+	id, err := parseChainId(name, s)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func parseAddress(name, s string) (common.Address, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil || len(b) != common.AddressLength {
+		return common.Address{}, fmt.Errorf("invalid %s address %q", name, s)
+	}
+	return common.BytesToAddress(b), nil
+}
+
+// CreateContractAddress computes the address of the contract deployed by the transaction sent
+// from the address with the nonce, which is the last 20 bytes of the hash of rlp([from, nonce])
+func CreateContractAddress(from common.Address, nonce uint64) common.Address {
+	hash := common2.RlpHash([]interface{}{from, nonce})
+	return common.BytesToAddress(hash[12:])
+}
+
 // Deprecated
 func (tx Transaction) hashSerialize() (string, error) {
 	toAddr := strings.ToLower(common.CleanHexPrefix(tx.To))